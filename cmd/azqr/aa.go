@@ -19,10 +19,10 @@ var aaCmd = &cobra.Command{
 	Long:  "Scan Azure Automation Account",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&aa.AutomationAccountScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&aa.AutomationAccountScanner{},
+			}
+		})
 	},
 }
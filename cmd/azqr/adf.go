@@ -19,10 +19,10 @@ var adfCmd = &cobra.Command{
 	Long:  "Scan Azure Data Factory",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&adf.DataFactoryScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&adf.DataFactoryScanner{},
+			}
+		})
 	},
 }
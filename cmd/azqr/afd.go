@@ -19,10 +19,10 @@ var afdCmd = &cobra.Command{
 	Long:  "Scan Azure Front Door",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&afd.FrontDoorScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&afd.FrontDoorScanner{},
+			}
+		})
 	},
 }
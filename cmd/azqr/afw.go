@@ -19,10 +19,10 @@ var afwCmd = &cobra.Command{
 	Long:  "Scan Azure Firewall",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&afw.FirewallScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&afw.FirewallScanner{},
+			}
+		})
 	},
 }
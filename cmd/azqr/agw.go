@@ -19,10 +19,10 @@ var agwCmd = &cobra.Command{
 	Long:  "Scan Azure Application Gateway",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&agw.ApplicationGatewayScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&agw.ApplicationGatewayScanner{},
+			}
+		})
 	},
 }
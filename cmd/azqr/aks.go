@@ -19,10 +19,10 @@ var aksCmd = &cobra.Command{
 	Long:  "Scan Azure Kubernetes Service",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&aks.AKSScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&aks.AKSScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var amgCmd = &cobra.Command{
 	Long:  "Scan Azure Managed Grafana",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&amg.ManagedGrafanaScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&amg.ManagedGrafanaScanner{},
+			}
+		})
 	},
 }
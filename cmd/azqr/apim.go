@@ -19,10 +19,10 @@ var apimCmd = &cobra.Command{
 	Long:  "Scan Azure API Management",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&apim.APIManagementScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&apim.APIManagementScanner{},
+			}
+		})
 	},
 }
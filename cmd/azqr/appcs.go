@@ -19,10 +19,10 @@ var appcsCmd = &cobra.Command{
 	Long:  "Scan Azure App Configuration",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&appcs.AppConfigurationScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&appcs.AppConfigurationScanner{},
+			}
+		})
 	},
 }
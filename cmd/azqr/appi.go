@@ -19,10 +19,10 @@ var appiCmd = &cobra.Command{
 	Long:  "Scan Azure Application Insights",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&appi.AppInsightsScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&appi.AppInsightsScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var asCmd = &cobra.Command{
 	Long:  "Scan Azure Analysis Service",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&as.AnalysisServicesScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&as.AnalysisServicesScanner{},
+			}
+		})
 	},
 }
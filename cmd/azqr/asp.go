@@ -19,10 +19,10 @@ var planCmd = &cobra.Command{
 	Long:  "Scan Azure App Service",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&asp.AppServiceScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&asp.AppServiceScanner{},
+			}
+		})
 	},
 }
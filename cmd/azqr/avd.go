@@ -19,10 +19,10 @@ var avdCmd = &cobra.Command{
 	Long:  "Scan Azure Virtual Desktop",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&avd.AzureVirtualDesktopScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&avd.AzureVirtualDesktopScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var avsCmd = &cobra.Command{
 	Long:  "Scan Azure VMware Solution",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&avs.AVSScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&avs.AVSScanner{},
+			}
+		})
 	},
 }
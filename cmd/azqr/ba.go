@@ -19,10 +19,10 @@ var baCmd = &cobra.Command{
 	Long:  "Scan Azure Batch Account",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&ba.BatchAccountScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&ba.BatchAccountScanner{},
+			}
+		})
 	},
 }
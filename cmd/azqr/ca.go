@@ -19,10 +19,10 @@ var caCmd = &cobra.Command{
 	Long:  "Scan Azure Container Apps",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&ca.ContainerAppsScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&ca.ContainerAppsScanner{},
+			}
+		})
 	},
 }
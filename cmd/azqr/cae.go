@@ -19,10 +19,10 @@ var caeCmd = &cobra.Command{
 	Long:  "Scan Azure Container Apps Environment",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&cae.ContainerAppsEnvironmentScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&cae.ContainerAppsEnvironmentScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var ciCmd = &cobra.Command{
 	Long:  "Scan Azure Container Instances",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&ci.ContainerInstanceScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&ci.ContainerInstanceScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var cogCmd = &cobra.Command{
 	Long:  "Scan Azure Cognitive Service Accounts",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&cog.CognitiveScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&cog.CognitiveScanner{},
+			}
+		})
 	},
 }
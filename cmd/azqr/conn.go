@@ -19,10 +19,10 @@ var conCmd = &cobra.Command{
 	Long:  "Scan Connection",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&conn.ConnectionScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&conn.ConnectionScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var cosmosCmd = &cobra.Command{
 	Long:  "Scan Azure Cosmos DB",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&cosmos.CosmosDBScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&cosmos.CosmosDBScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var crCmd = &cobra.Command{
 	Long:  "Scan Azure Container Registries",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&cr.ContainerRegistryScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&cr.ContainerRegistryScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var dbwCmd = &cobra.Command{
 	Long:  "Scan Azure Databricks",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&dbw.DatabricksScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&dbw.DatabricksScanner{},
+			}
+		})
 	},
 }
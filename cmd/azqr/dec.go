@@ -19,10 +19,10 @@ var decCmd = &cobra.Command{
 	Long:  "Scan Azure Data Explorer",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&dec.DataExplorerScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&dec.DataExplorerScanner{},
+			}
+		})
 	},
 }
@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"github.com/Azure/azqr/internal"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	diffCmd.PersistentFlags().StringP("previous", "p", "", "Snapshot file of the previous scan")
+	diffCmd.PersistentFlags().StringP("current", "c", "", "Snapshot file of the current scan")
+	diffCmd.PersistentFlags().StringP("output-name", "o", "", "Output file name without extension")
+	diffCmd.PersistentFlags().BoolP("xlsx", "", false, "Create excel file of the current scan with a Changes sheet")
+	diffCmd.PersistentFlags().BoolP("json", "", false, "Create json file with the changes")
+	diffCmd.PersistentFlags().BoolP("mask", "m", true, "Mask the subscription id in the report (default)")
+	diffCmd.PersistentFlags().BoolP("debug", "", false, "Set log level to debug")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two scan snapshots",
+	Long:  "Compare two scan snapshots and report new, resolved and unchanged findings and inventory changes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		previous, _ := cmd.Flags().GetString("previous")
+		current, _ := cmd.Flags().GetString("current")
+		outputFileName, _ := cmd.Flags().GetString("output-name")
+		xlsx, _ := cmd.Flags().GetBool("xlsx")
+		json, _ := cmd.Flags().GetBool("json")
+		mask, _ := cmd.Flags().GetBool("mask")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		params := internal.DiffParams{
+			PreviousFile: previous,
+			CurrentFile:  current,
+			OutputName:   outputFileName,
+			Mask:         mask,
+			Xlsx:         xlsx,
+			Json:         json,
+			Debug:        debug,
+		}
+
+		differ := internal.Differ{}
+		if err := differ.Diff(&params); err != nil {
+			log.Fatal().Err(err).Msg("Failed to compare scans")
+		}
+	},
+}
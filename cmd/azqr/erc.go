@@ -19,10 +19,10 @@ var ercCmd = &cobra.Command{
 	Long:  "Scan Express Route Circuits",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&erc.ExpressRouteScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&erc.ExpressRouteScanner{},
+			}
+		})
 	},
 }
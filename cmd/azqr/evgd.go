@@ -19,10 +19,10 @@ var evgdCmd = &cobra.Command{
 	Long:  "Scan Azure Event Grid Domains",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&evgd.EventGridScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&evgd.EventGridScanner{},
+			}
+		})
 	},
 }
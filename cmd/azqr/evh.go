@@ -19,10 +19,10 @@ var evhCmd = &cobra.Command{
 	Long:  "Scan Azure Event Hubs",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&evh.EventHubScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&evh.EventHubScanner{},
+			}
+		})
 	},
 }
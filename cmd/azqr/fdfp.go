@@ -19,10 +19,10 @@ var fdfpCmd = &cobra.Command{
 	Long:  "Scan Front Door Web Application Policy",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&fdfp.FrontDoorWAFPolicyScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&fdfp.FrontDoorWAFPolicyScanner{},
+			}
+		})
 	},
 }
@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"github.com/Azure/azqr/internal"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	filtersValidateCmd.PersistentFlags().StringP("rules-dir", "", "", "Directory with custom rules files (YAML format)")
+	filtersValidateCmd.PersistentFlags().StringP("aprl-rules-dir", "", "", "Directory with custom recommendations in the APRL format (YAML files and kql/*.kql queries)")
+	filtersValidateCmd.PersistentFlags().BoolP("debug", "", false, "Set log level to debug")
+
+	filtersCmd.AddCommand(filtersValidateCmd)
+	rootCmd.AddCommand(filtersCmd)
+}
+
+var filtersCmd = &cobra.Command{
+	Use:   "filters",
+	Short: "Manage filters files",
+	Long:  "Manage the filters files (YAML format) used with the --filters option of the scan command",
+	Args:  cobra.NoArgs,
+}
+
+var filtersValidateCmd = &cobra.Command{
+	Use:   "validate <filters-file>",
+	Short: "Validate a filters file",
+	Long:  "Validate a filters file: unknown keys, unknown recommendation ids and malformed subscription, resource group or resource ids are errors. Contradictory include and exclude entries are warnings.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rulesDir, _ := cmd.Flags().GetString("rules-dir")
+		aprlRulesDir, _ := cmd.Flags().GetString("aprl-rules-dir")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		params := internal.ValidateFiltersParams{
+			FilterFile:   args[0],
+			RulesDir:     rulesDir,
+			AprlRulesDir: aprlRulesDir,
+			Debug:        debug,
+		}
+
+		validator := internal.FiltersValidator{}
+		if err := validator.Validate(&params); err != nil {
+			log.Fatal().Err(err).Msg("Invalid filters file")
+		}
+	},
+}
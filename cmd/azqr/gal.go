@@ -19,10 +19,10 @@ var galCmd = &cobra.Command{
 	Long:  "Scan Azure Galleries",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&gal.GalleryScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&gal.GalleryScanner{},
+			}
+		})
 	},
 }
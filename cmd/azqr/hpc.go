@@ -19,10 +19,10 @@ var hpcCmd = &cobra.Command{
 	Long:  "Scan HPC",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&hpc.HighPerformanceComputingScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&hpc.HighPerformanceComputingScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var iotCmd = &cobra.Command{
 	Long:  "Scan Azure IoT Hub",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&iot.IoTHubScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&iot.IoTHubScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var itCmd = &cobra.Command{
 	Long:  "Scan Image Template",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&it.ImageTemplateScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&it.ImageTemplateScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var kvCmd = &cobra.Command{
 	Long:  "Scan Azure Key Vault",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&kv.KeyVaultScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&kv.KeyVaultScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var lbCmd = &cobra.Command{
 	Long:  "Scan Azure Load Balancer",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&lb.LoadBalancerScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&lb.LoadBalancerScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var logCmd = &cobra.Command{
 	Long:  "Scan Log Analytics workspace",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&log.LogAnalyticsScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&log.LogAnalyticsScanner{},
+			}
+		})
 	},
 }
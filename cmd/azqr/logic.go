@@ -19,10 +19,10 @@ var logicCmd = &cobra.Command{
 	Long:  "Scan Azure Logic Apps",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&logic.LogicAppScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&logic.LogicAppScanner{},
+			}
+		})
 	},
 }
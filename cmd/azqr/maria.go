@@ -19,10 +19,10 @@ var mariaCmd = &cobra.Command{
 	Long:  "Scan Azure Database for MariaDB",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&maria.MariaScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&maria.MariaScanner{},
+			}
+		})
 	},
 }
@@ -19,11 +19,11 @@ var mysqlCmd = &cobra.Command{
 	Long:  "Scan Azure Database for MySQL",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&mysql.MySQLScanner{},
-			&mysql.MySQLFlexibleScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&mysql.MySQLScanner{},
+				&mysql.MySQLFlexibleScanner{},
+			}
+		})
 	},
 }
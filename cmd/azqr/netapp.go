@@ -19,10 +19,10 @@ var netappCmd = &cobra.Command{
 	Long:  "Scan NetApp",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&netapp.NetAppScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&netapp.NetAppScanner{},
+			}
+		})
 	},
 }
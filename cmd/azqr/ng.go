@@ -19,10 +19,10 @@ var ngCmd = &cobra.Command{
 	Long:  "Scan Azure NAT Gateway",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&ng.NatGatewayScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&ng.NatGatewayScanner{},
+			}
+		})
 	},
 }
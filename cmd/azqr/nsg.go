@@ -19,10 +19,10 @@ var nsgCmd = &cobra.Command{
 	Long:  "Scan NSG",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&nsg.NSGScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&nsg.NSGScanner{},
+			}
+		})
 	},
 }
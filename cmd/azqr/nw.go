@@ -19,10 +19,10 @@ var nwCmd = &cobra.Command{
 	Long:  "Scan Network Watcher",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&nw.NetworkWatcherScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&nw.NetworkWatcherScanner{},
+			}
+		})
 	},
 }
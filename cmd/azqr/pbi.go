@@ -5,6 +5,7 @@ package azqr
 
 import (
 	"github.com/Azure/azqr/internal/renderers/pbi"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +21,8 @@ var pbiCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		path, _ := cmd.Flags().GetString("template-path")
-		pbi.CreatePBIReport(path)
+		if err := pbi.CreatePBIReport(path); err != nil {
+			log.Fatal().Err(err).Msg("Failed to create Power BI template")
+		}
 	},
 }
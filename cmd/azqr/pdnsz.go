@@ -19,10 +19,10 @@ var pdnszCmd = &cobra.Command{
 	Long:  "Scan Private DNS Zone",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&pdnsz.PrivateDNSZoneScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&pdnsz.PrivateDNSZoneScanner{},
+			}
+		})
 	},
 }
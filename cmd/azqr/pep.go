@@ -19,10 +19,10 @@ var pepCmd = &cobra.Command{
 	Long:  "Scan Private Endpoint",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&pep.PrivateEndpointScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&pep.PrivateEndpointScanner{},
+			}
+		})
 	},
 }
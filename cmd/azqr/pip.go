@@ -19,10 +19,10 @@ var pipCmd = &cobra.Command{
 	Long:  "Scan Public IP",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&pip.PublicIPScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&pip.PublicIPScanner{},
+			}
+		})
 	},
 }
@@ -19,11 +19,11 @@ var psqlCmd = &cobra.Command{
 	Long:  "Scan Azure Database for psql",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&psql.PostgreScanner{},
-			&psql.PostgreFlexibleScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&psql.PostgreScanner{},
+				&psql.PostgreFlexibleScanner{},
+			}
+		})
 	},
 }
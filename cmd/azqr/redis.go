@@ -19,10 +19,10 @@ var redisCmd = &cobra.Command{
 	Long:  "Scan Azure Cache for Redis",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&redis.RedisScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&redis.RedisScanner{},
+			}
+		})
 	},
 }
@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"github.com/Azure/azqr/internal"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	renderCmd.PersistentFlags().StringP("input", "i", "", "Snapshot file created with scan --snapshot")
+	renderCmd.PersistentFlags().StringP("output-name", "o", "", "Output file name without extension")
+	renderCmd.PersistentFlags().BoolP("json", "", false, "Create json file")
+	renderCmd.PersistentFlags().BoolP("csv", "", false, "Create csv files")
+	renderCmd.PersistentFlags().BoolP("sarif", "", false, "Create sarif file")
+	renderCmd.PersistentFlags().BoolP("junit", "", false, "Create junit xml file")
+	renderCmd.PersistentFlags().BoolP("html", "", false, "Create html file")
+	renderCmd.PersistentFlags().BoolP("markdown", "", false, "Create markdown executive summary")
+	renderCmd.PersistentFlags().StringP("markdown-template", "", "", "Template file (Go text/template format) of the markdown executive summary")
+	renderCmd.PersistentFlags().BoolP("ndjson", "", false, "Create newline-delimited json files with the tables of the report")
+	renderCmd.PersistentFlags().BoolP("parquet", "", false, "Create parquet files with the tables of the report")
+	renderCmd.PersistentFlags().StringP("excel-profile", "", "", "Workbook profile (YAML format) with the sheets, columns, sort order, colors and logo of the excel report")
+	renderCmd.PersistentFlags().BoolP("mask", "m", true, "Mask the subscription id in the report (default)")
+	renderCmd.PersistentFlags().BoolP("debug", "", false, "Set log level to debug")
+
+	rootCmd.AddCommand(renderCmd)
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render reports from a scan snapshot",
+	Long:  "Render reports from a scan snapshot without connecting to Azure",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input, _ := cmd.Flags().GetString("input")
+		outputFileName, _ := cmd.Flags().GetString("output-name")
+		csv, _ := cmd.Flags().GetBool("csv")
+		json, _ := cmd.Flags().GetBool("json")
+		sarif, _ := cmd.Flags().GetBool("sarif")
+		junit, _ := cmd.Flags().GetBool("junit")
+		html, _ := cmd.Flags().GetBool("html")
+		markdown, _ := cmd.Flags().GetBool("markdown")
+		markdownTemplate, _ := cmd.Flags().GetString("markdown-template")
+		ndjson, _ := cmd.Flags().GetBool("ndjson")
+		parquet, _ := cmd.Flags().GetBool("parquet")
+		excelProfile, _ := cmd.Flags().GetString("excel-profile")
+		mask, _ := cmd.Flags().GetBool("mask")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		params := internal.RenderParams{
+			InputFile:        input,
+			OutputName:       outputFileName,
+			Mask:             mask,
+			Csv:              csv,
+			Json:             json,
+			Sarif:            sarif,
+			JUnit:            junit,
+			Html:             html,
+			Markdown:         markdown,
+			MarkdownTemplate: markdownTemplate,
+			Ndjson:           ndjson,
+			Parquet:          parquet,
+			ExcelProfile:     excelProfile,
+			Debug:            debug,
+		}
+
+		renderer := internal.Renderer{}
+		if err := renderer.Render(&params); err != nil {
+			log.Fatal().Err(err).Msg("Failed to render reports")
+		}
+	},
+}
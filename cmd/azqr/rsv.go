@@ -19,10 +19,10 @@ var rsvCmd = &cobra.Command{
 	Long:  "Scan Recovery Service",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&rsv.RecoveryServiceScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&rsv.RecoveryServiceScanner{},
+			}
+		})
 	},
 }
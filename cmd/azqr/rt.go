@@ -19,10 +19,10 @@ var rtCmd = &cobra.Command{
 	Long:  "Scan Route Table",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&rt.RouteTableScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&rt.RouteTableScanner{},
+			}
+		})
 	},
 }
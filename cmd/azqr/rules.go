@@ -11,10 +11,12 @@ import (
 	"github.com/Azure/azqr/internal"
 	"github.com/Azure/azqr/internal/azqr"
 	"github.com/Azure/azqr/internal/scanners"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	rulesCmd.PersistentFlags().StringP("aprl-rules-dir", "", "", "Directory with custom recommendations in the APRL format (YAML files and kql/*.kql queries)")
 	rootCmd.AddCommand(rulesCmd)
 }
 
@@ -25,8 +27,12 @@ var rulesCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		serviceScanners := scanners.GetScanners()
-		aprlScanner := internal.AprlScanner{}
-		aprl := aprlScanner.GetAprlRecommendations()
+		aprlRulesDir, _ := cmd.Flags().GetString("aprl-rules-dir")
+		aprlScanner := internal.AprlScanner{RulesDir: aprlRulesDir}
+		aprl, err := aprlScanner.GetAprlRecommendations()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load APRL recommendations")
+		}
 
 		fmt.Println("#  | Id | Resource Type | Category | Impact | Recommendation | Learn")
 		fmt.Println("---|---|---|---|---|---|---")
@@ -19,10 +19,10 @@ var sapCmd = &cobra.Command{
 	Long:  "Scan SAP",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&sap.SAPScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&sap.SAPScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var sbCmd = &cobra.Command{
 	Long:  "Scan Azure Service Bus",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&sb.ServiceBusScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&sb.ServiceBusScanner{},
+			}
+		})
 	},
 }
@@ -6,7 +6,11 @@ package azqr
 import (
 	"github.com/Azure/azqr/internal"
 	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/metrics"
+	"github.com/Azure/azqr/internal/notify"
 	"github.com/Azure/azqr/internal/scanners"
+	"github.com/Azure/azqr/internal/throttling"
+	"github.com/rs/zerolog/log"
 
 	"github.com/spf13/cobra"
 )
@@ -14,17 +18,46 @@ import (
 func init() {
 	scanCmd.PersistentFlags().StringP("subscription-id", "s", "", "Azure Subscription Id")
 	scanCmd.PersistentFlags().StringP("resource-group", "g", "", "Azure Resource Group (Use with --subscription-id)")
+	scanCmd.PersistentFlags().StringP("management-group", "", "", "Azure Management Group Id. Scans the subscriptions of the management group and its descendants")
 	scanCmd.PersistentFlags().BoolP("defender", "d", true, "Scan Defender Status (default)")
 	scanCmd.PersistentFlags().BoolP("advisor", "a", true, "Scan Azure Advisor Recommendations (default)")
 	scanCmd.PersistentFlags().BoolP("costs", "c", true, "Scan Azure Costs (default)")
 	scanCmd.PersistentFlags().BoolP("json", "", false, "Create josn file")
 	scanCmd.PersistentFlags().BoolP("csv", "", false, "Create csv files")
+	scanCmd.PersistentFlags().BoolP("sarif", "", false, "Create sarif file")
+	scanCmd.PersistentFlags().BoolP("junit", "", false, "Create junit xml file")
+	scanCmd.PersistentFlags().BoolP("html", "", false, "Create html file")
+	scanCmd.PersistentFlags().BoolP("markdown", "", false, "Create markdown executive summary")
+	scanCmd.PersistentFlags().StringP("markdown-template", "", "", "Template file (Go text/template format) of the markdown executive summary")
+	scanCmd.PersistentFlags().BoolP("ndjson", "", false, "Create newline-delimited json files with the tables of the report")
+	scanCmd.PersistentFlags().BoolP("parquet", "", false, "Create parquet files with the tables of the report")
+	scanCmd.PersistentFlags().StringP("excel-profile", "", "", "Workbook profile (YAML format) with the sheets, columns, sort order, colors and logo of the excel report")
+	scanCmd.PersistentFlags().StringP("fail-on-impact", "", "", "Exit with a non-zero code when a finding with this impact or higher is found (High, Medium or Low)")
+	scanCmd.PersistentFlags().BoolP("snapshot", "", false, "Create a snapshot file that can be rendered later with the render command")
 	scanCmd.PersistentFlags().StringP("output-name", "o", "", "Output file name without extension")
 	scanCmd.PersistentFlags().BoolP("mask", "m", true, "Mask the subscription id in the report (default)")
 	scanCmd.PersistentFlags().BoolP("azure-cli-credential", "f", false, "Force the use of Azure CLI Credential")
 	scanCmd.PersistentFlags().BoolP("debug", "", false, "Set log level to debug")
 	scanCmd.PersistentFlags().StringP("filters", "e", "", "Filters file (YAML format)")
+	scanCmd.PersistentFlags().StringP("ignore-tag", "", "azqr-ignore", "Tag of the resources that opt out of recommendations. Its value is a comma-separated list of recommendation ids, or *")
+	scanCmd.PersistentFlags().StringP("rules-dir", "", "", "Directory with custom rules files (YAML format)")
+	scanCmd.PersistentFlags().StringP("aprl-rules-dir", "", "", "Directory with custom recommendations in the APRL format (YAML files and kql/*.kql queries)")
 	scanCmd.PersistentFlags().BoolP("azqr", "", true, "Scan Azure Quick Review Recommendations (default)")
+	scanCmd.PersistentFlags().BoolP("fail-fast", "", false, "Abort the scan on the first error instead of recording it in the report")
+	scanCmd.PersistentFlags().BoolP("batch-aprl-queries", "", true, "Combine the APRL queries of the same resource type in a single Resource Graph query (default)")
+	scanCmd.PersistentFlags().IntP("max-concurrency", "", throttling.DefaultMaxConcurrency, "Maximum number of in-flight ARM requests across all subscriptions")
+	scanCmd.PersistentFlags().IntP("subscription-concurrency", "", internal.DefaultSubscriptionConcurrency, "Maximum number of subscriptions scanned at the same time")
+	scanCmd.PersistentFlags().StringP("state-dir", "", "", "Directory where completed scan units are checkpointed, so an interrupted scan can be resumed")
+	scanCmd.PersistentFlags().StringP("resume", "", "", "Resume an interrupted scan from its state directory (use the same flags as the interrupted scan)")
+	scanCmd.PersistentFlags().StringP("metrics-endpoint", "", "", "Publish posture metrics to this OTLP/HTTP (e.g. http://localhost:4318/v1/metrics) or pushgateway (e.g. http://localhost:9091) endpoint")
+	scanCmd.PersistentFlags().StringP("metrics-protocol", "", metrics.ProtocolOtlp, "Protocol of the metrics endpoint: otlp or pushgateway")
+	scanCmd.PersistentFlags().StringP("webhook-url", "", "", "Post a notification with the summary of the findings to this URL after the reports are created")
+	scanCmd.PersistentFlags().StringP("webhook-template", "", "", "Template file (Go text/template format) of the json payload of the webhook notification")
+	scanCmd.PersistentFlags().StringArrayP("webhook-header", "", []string{}, "Header of the webhook request in the \"Name: value\" format (can be repeated)")
+	scanCmd.PersistentFlags().StringP("webhook-baseline", "", "", "Snapshot file of a previous scan. Only findings that are not in it are reported as new")
+	scanCmd.PersistentFlags().IntP("webhook-top", "", notify.DefaultTop, "Maximum number of new High impact findings in the webhook notification")
+	scanCmd.PersistentFlags().IntP("webhook-retries", "", notify.DefaultRetries, "Number of times a failed webhook notification is retried")
+	scanCmd.PersistentFlags().BoolP("webhook-dry-run", "", false, "Print the webhook payload instead of posting it")
 
 	rootCmd.AddCommand(scanCmd)
 }
@@ -35,43 +68,107 @@ var scanCmd = &cobra.Command{
 	Long:  "Scan Azure Resources",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := scanners.GetScanners()
-		scan(cmd, serviceScanners)
+		scan(cmd, scanners.GetScanners)
 	},
 }
 
-func scan(cmd *cobra.Command, serviceScanners []azqr.IAzureScanner) {
+// scan runs the scan with the scanners created by newScanners. Each subscription is scanned with its own instances.
+func scan(cmd *cobra.Command, newScanners func() []azqr.IAzureScanner) {
 	subscriptionID, _ := cmd.Flags().GetString("subscription-id")
 	resourceGroupName, _ := cmd.Flags().GetString("resource-group")
+	managementGroupID, _ := cmd.Flags().GetString("management-group")
 	outputFileName, _ := cmd.Flags().GetString("output-name")
 	defender, _ := cmd.Flags().GetBool("defender")
 	advisor, _ := cmd.Flags().GetBool("advisor")
 	cost, _ := cmd.Flags().GetBool("costs")
 	csv, _ := cmd.Flags().GetBool("csv")
 	json, _ := cmd.Flags().GetBool("json")
+	sarif, _ := cmd.Flags().GetBool("sarif")
+	junit, _ := cmd.Flags().GetBool("junit")
+	html, _ := cmd.Flags().GetBool("html")
+	markdown, _ := cmd.Flags().GetBool("markdown")
+	markdownTemplate, _ := cmd.Flags().GetString("markdown-template")
+	ndjson, _ := cmd.Flags().GetBool("ndjson")
+	parquet, _ := cmd.Flags().GetBool("parquet")
+	excelProfile, _ := cmd.Flags().GetString("excel-profile")
+	failOnImpact, _ := cmd.Flags().GetString("fail-on-impact")
+	snapshot, _ := cmd.Flags().GetBool("snapshot")
 	mask, _ := cmd.Flags().GetBool("mask")
 	debug, _ := cmd.Flags().GetBool("debug")
 	forceAzureCliCredential, _ := cmd.Flags().GetBool("azure-cli-credential")
 	filtersFile, _ := cmd.Flags().GetString("filters")
+	ignoreTag, _ := cmd.Flags().GetString("ignore-tag")
+	rulesDir, _ := cmd.Flags().GetString("rules-dir")
+	aprlRulesDir, _ := cmd.Flags().GetString("aprl-rules-dir")
 	azqr, _ := cmd.Flags().GetBool("azqr")
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+	batchAprlQueries, _ := cmd.Flags().GetBool("batch-aprl-queries")
+	maxConcurrency, _ := cmd.Flags().GetInt("max-concurrency")
+	subscriptionConcurrency, _ := cmd.Flags().GetInt("subscription-concurrency")
+	stateDir, _ := cmd.Flags().GetString("state-dir")
+	resume, _ := cmd.Flags().GetString("resume")
+	metricsEndpoint, _ := cmd.Flags().GetString("metrics-endpoint")
+	metricsProtocol, _ := cmd.Flags().GetString("metrics-protocol")
+	webhookURL, _ := cmd.Flags().GetString("webhook-url")
+	webhookTemplate, _ := cmd.Flags().GetString("webhook-template")
+	webhookHeaders, _ := cmd.Flags().GetStringArray("webhook-header")
+	webhookBaseline, _ := cmd.Flags().GetString("webhook-baseline")
+	webhookTop, _ := cmd.Flags().GetInt("webhook-top")
+	webhookRetries, _ := cmd.Flags().GetInt("webhook-retries")
+	webhookDryRun, _ := cmd.Flags().GetBool("webhook-dry-run")
+
+	if resume != "" {
+		stateDir = resume
+	}
 
 	params := internal.ScanParams{
 		SubscriptionID:          subscriptionID,
 		ResourceGroup:           resourceGroupName,
+		ManagementGroup:         managementGroupID,
 		OutputName:              outputFileName,
 		Defender:                defender,
 		Advisor:                 advisor,
 		Cost:                    cost,
 		Csv:                     csv,
 		Json:                    json,
+		Sarif:                   sarif,
+		JUnit:                   junit,
+		Html:                    html,
+		Markdown:                markdown,
+		MarkdownTemplate:        markdownTemplate,
+		Ndjson:                  ndjson,
+		Parquet:                 parquet,
+		ExcelProfile:            excelProfile,
+		IgnoreTag:               ignoreTag,
+		FailOnImpact:            failOnImpact,
+		MetricsEndpoint:         metricsEndpoint,
+		MetricsProtocol:         metricsProtocol,
+		WebhookURL:              webhookURL,
+		WebhookTemplate:         webhookTemplate,
+		WebhookHeaders:          webhookHeaders,
+		WebhookBaseline:         webhookBaseline,
+		WebhookTop:              webhookTop,
+		WebhookRetries:          webhookRetries,
+		WebhookDryRun:           webhookDryRun,
+		Snapshot:                snapshot,
 		Mask:                    mask,
 		Debug:                   debug,
-		ServiceScanners:         serviceScanners,
+		ServiceScanners:         newScanners,
 		ForceAzureCliCredential: forceAzureCliCredential,
 		FilterFile:              filtersFile,
+		RulesDir:                rulesDir,
+		AprlRulesDir:            aprlRulesDir,
 		UseAzqrRecommendations:  azqr,
+		BatchAprlQueries:        batchAprlQueries,
+		FailFast:                failFast,
+		MaxConcurrency:          maxConcurrency,
+		SubscriptionConcurrency: subscriptionConcurrency,
+		StateDir:                stateDir,
+		Resume:                  resume != "",
 	}
 
 	scanner := internal.Scanner{}
-	scanner.Scan(&params)
+	if _, err := scanner.Scan(&params); err != nil {
+		log.Fatal().Err(err).Msg("Scan failed")
+	}
 }
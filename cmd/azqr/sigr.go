@@ -19,10 +19,10 @@ var sigrCmd = &cobra.Command{
 	Long:  "Scan Azure SignalR",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&sigr.SignalRScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&sigr.SignalRScanner{},
+			}
+		})
 	},
 }
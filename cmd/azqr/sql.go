@@ -19,10 +19,10 @@ var sqlCmd = &cobra.Command{
 	Long:  "Scan Azure SQL Database",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&sql.SQLScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&sql.SQLScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var stCmd = &cobra.Command{
 	Long:  "Scan Azure Storage",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&st.StorageScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&st.StorageScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var synwCmd = &cobra.Command{
 	Long:  "Scan Azure Synapse Workspace",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&synw.SynapseWorkspaceScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&synw.SynapseWorkspaceScanner{},
+			}
+		})
 	},
 }
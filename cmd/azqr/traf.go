@@ -19,10 +19,10 @@ var trafCmd = &cobra.Command{
 	Long:  "Scan Azure Traffic Manager",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&traf.TrafficManagerScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&traf.TrafficManagerScanner{},
+			}
+		})
 	},
 }
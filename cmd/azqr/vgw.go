@@ -19,10 +19,10 @@ var vgwCmd = &cobra.Command{
 	Long:  "Scan Virtual Network Gateway",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&vgw.VirtualNetworkGatewayScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&vgw.VirtualNetworkGatewayScanner{},
+			}
+		})
 	},
 }
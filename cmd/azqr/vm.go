@@ -19,10 +19,10 @@ var vmCmd = &cobra.Command{
 	Long:  "Scan Virtual Machine",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&vm.VirtualMachineScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&vm.VirtualMachineScanner{},
+			}
+		})
 	},
 }
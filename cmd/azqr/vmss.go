@@ -19,10 +19,10 @@ var vmssCmd = &cobra.Command{
 	Long:  "Scan Virtual Machine Scale Set",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&vmss.VirtualMachineScaleSetScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&vmss.VirtualMachineScaleSetScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var vnetCmd = &cobra.Command{
 	Long:  "Scan Azure Virtual Network",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&vnet.VirtualNetworkScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&vnet.VirtualNetworkScanner{},
+			}
+		})
 	},
 }
@@ -19,10 +19,10 @@ var vwanCmd = &cobra.Command{
 	Long:  "Scan Azure Virtual WAN",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&vwan.VirtualWanScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&vwan.VirtualWanScanner{},
+			}
+		})
 	},
 }
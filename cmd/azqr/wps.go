@@ -19,10 +19,10 @@ var wpsCmd = &cobra.Command{
 	Long:  "Scan Azure Web PubSub",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		serviceScanners := []azqr.IAzureScanner{
-			&wps.WebPubSubScanner{},
-		}
-
-		scan(cmd, serviceScanners)
+		scan(cmd, func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{
+				&wps.WebPubSubScanner{},
+			}
+		})
 	},
 }
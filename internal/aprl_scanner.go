@@ -7,14 +7,17 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"math"
+	"os"
+	"sort"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/checkpoint"
 	"github.com/Azure/azqr/internal/graph"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/rs/zerolog/log"
@@ -27,21 +30,67 @@ import (
 //go:embed aprl/azure-specialized-workloads/**/kql/*.kql
 var embededFiles embed.FS
 
+const (
+	// aprlSource - Source of the recommendations of the Azure Proactive Resiliency Library
+	aprlSource = "APRL"
+	// customSource - Source of the recommendations loaded from the custom APRL rules directory
+	customSource = "CUSTOM"
+)
+
 type (
-	AprlScanner struct{}
+	AprlScanner struct {
+		// BatchQueries combines the queries of the rules targeting the same resource type in a single Resource Graph query
+		BatchQueries bool
+		// RulesDir is a directory with additional recommendations in the APRL format (YAML files and kql/*.kql queries)
+		RulesDir string
+		// ManagementGroup runs the queries at the scope of the management group instead of batches of subscriptions
+		ManagementGroup string
+	}
 )
 
-// GetAprlRecommendations returns a map with all APRL recommendations
-func (sc AprlScanner) GetAprlRecommendations() map[string]map[string]azqr.AprlRecommendation {
+// GetAprlRecommendations returns a map with all APRL recommendations, including the custom recommendations of RulesDir
+func (sc AprlScanner) GetAprlRecommendations() (map[string]map[string]azqr.AprlRecommendation, error) {
 	r := map[string]map[string]azqr.AprlRecommendation{}
 
 	fsys, err := fs.Sub(embededFiles, "aprl/azure-resources")
 	if err != nil {
-		return nil
+		return nil, err
+	}
+
+	aprl, err := readAprlRecommendations(fsys, aprlSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APRL recommendations: %w", err)
+	}
+
+	for _, recommendation := range aprl {
+		addAprlRecommendation(r, recommendation)
+	}
+
+	if sc.RulesDir == "" {
+		return r, nil
+	}
+
+	custom, err := readAprlRecommendations(os.DirFS(sc.RulesDir), customSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom APRL recommendations from %s: %w", sc.RulesDir, err)
+	}
+
+	for _, recommendation := range custom {
+		if err := validateCustomAprlRecommendation(recommendation, r); err != nil {
+			return nil, fmt.Errorf("invalid custom APRL recommendation in %s: %w", sc.RulesDir, err)
+		}
+		addAprlRecommendation(r, recommendation)
 	}
 
+	log.Info().Msgf("Loaded %d custom APRL recommendations from %s", len(custom), sc.RulesDir)
+	return r, nil
+}
+
+// readAprlRecommendations reads the recommendations of all the YAML files of fsys.
+// The query of a recommendation is read from the kql file named after its id, if any.
+func readAprlRecommendations(fsys fs.FS, source string) ([]azqr.AprlRecommendation, error) {
 	q := map[string]string{}
-	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -57,14 +106,15 @@ func (sc AprlScanner) GetAprlRecommendations() map[string]map[string]azqr.AprlRe
 		return nil
 	})
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
+	r := []azqr.AprlRecommendation{}
 	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+		if !d.IsDir() && (strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
 			content, err := fs.ReadFile(fsys, path)
 			if err != nil {
 				return err
@@ -73,41 +123,69 @@ func (sc AprlScanner) GetAprlRecommendations() map[string]map[string]azqr.AprlRe
 			var recommendations []azqr.AprlRecommendation
 			err = yaml.Unmarshal(content, &recommendations)
 			if err != nil {
-				return err
+				return fmt.Errorf("%s: %w", path, err)
 			}
 
 			for _, recommendation := range recommendations {
-				t := strings.ToLower(recommendation.ResourceType)
-				if _, ok := r[t]; !ok {
-					r[t] = map[string]azqr.AprlRecommendation{}
-				}
-
 				if i, ok := q[recommendation.RecommendationID]; ok {
 					recommendation.GraphQuery = i
 				}
-
-				r[t][recommendation.RecommendationID] = recommendation
+				recommendation.Source = source
+				r = append(r, recommendation)
 			}
-
 		}
 		return nil
 	})
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return r
+	return r, nil
+}
+
+// validateCustomAprlRecommendation checks that a custom recommendation can be reported and doesn't replace another recommendation
+func validateCustomAprlRecommendation(recommendation azqr.AprlRecommendation, aprl map[string]map[string]azqr.AprlRecommendation) error {
+	if recommendation.RecommendationID == "" {
+		return errors.New("aprlGuid is required")
+	}
+	if recommendation.ResourceType == "" {
+		return fmt.Errorf("recommendation %s: recommendationResourceType is required", recommendation.RecommendationID)
+	}
+	if len(recommendation.LearnMoreLink) == 0 {
+		return fmt.Errorf("recommendation %s: learnMoreLink is required", recommendation.RecommendationID)
+	}
+
+	for _, rt := range aprl {
+		if r, ok := rt[recommendation.RecommendationID]; ok {
+			if r.Source == customSource {
+				return fmt.Errorf("recommendation %s is defined more than once", recommendation.RecommendationID)
+			}
+			return fmt.Errorf("recommendation %s conflicts with an APRL recommendation", recommendation.RecommendationID)
+		}
+	}
+	return nil
+}
+
+func addAprlRecommendation(r map[string]map[string]azqr.AprlRecommendation, recommendation azqr.AprlRecommendation) {
+	t := strings.ToLower(recommendation.ResourceType)
+	if _, ok := r[t]; !ok {
+		r[t] = map[string]azqr.AprlRecommendation{}
+	}
+	r[t][recommendation.RecommendationID] = recommendation
 }
 
 // AprlScan scans Azure resources using Azure Proactive Resiliency Library v2 (APRL)
-func (sc AprlScanner) Scan(ctx context.Context, cred azcore.TokenCredential, serviceScanners []azqr.IAzureScanner, filters *azqr.Filters, subscriptions map[string]string) (map[string]map[string]azqr.AprlRecommendation, []azqr.AprlResult) {
+// Failed queries do not stop the scan, their errors are returned along with the results of the other queries.
+// Completed batches are checkpointed to the state store and skipped when the scan is resumed.
+func (sc AprlScanner) Scan(ctx context.Context, cred azcore.TokenCredential, aprl map[string]map[string]azqr.AprlRecommendation, serviceScanners []azqr.IAzureScanner, filters *azqr.Filters, subscriptions map[string]string, state *checkpoint.Store) (map[string]map[string]azqr.AprlRecommendation, []azqr.AprlResult, error) {
 	recommendations := map[string]map[string]azqr.AprlRecommendation{}
 	results := []azqr.AprlResult{}
 	rules := []azqr.AprlRecommendation{}
-	graph := graph.NewGraphQuery(cred)
-
-	// get APRL recommendations
-	aprl := sc.GetAprlRecommendations()
+	graph, err := graph.NewGraphQuery(cred)
+	if err != nil {
+		return recommendations, results, err
+	}
+	graph.ScopeToManagementGroup(sc.ManagementGroup)
 
 	for _, s := range serviceScanners {
 		for _, t := range s.ResourceTypes() {
@@ -126,16 +204,30 @@ func (sc AprlScanner) Scan(ctx context.Context, cred azcore.TokenCredential, ser
 		}
 	}
 
+	// sort the rules so batches are the same across runs and can be resumed.
+	// Rules of the same resource type are kept together, so their queries can be combined.
+	sort.Slice(rules, func(i, j int) bool {
+		if !strings.EqualFold(rules[i].ResourceType, rules[j].ResourceType) {
+			return strings.ToLower(rules[i].ResourceType) < strings.ToLower(rules[j].ResourceType)
+		}
+		return rules[i].RecommendationID < rules[j].RecommendationID
+	})
+
+	subscriptionIDs := make([]string, 0, len(subscriptions))
+	for s := range subscriptions {
+		subscriptionIDs = append(subscriptionIDs, s)
+	}
+
 	batches := int(math.Ceil(float64(len(rules)) / 12))
 
-	jobs := make(chan []azqr.AprlRecommendation, batches)
-	ch := make(chan []azqr.AprlResult, batches)
+	jobs := make(chan aprlBatch, batches)
+	ch := make(chan aprlBatchResult, batches)
 	var wg sync.WaitGroup
 
 	// Start workers
 	numWorkers := 12 // Define the number of workers in the pool
 	for w := 0; w < numWorkers; w++ {
-		go sc.worker(ctx, graph, subscriptions, jobs, ch, &wg)
+		go sc.worker(ctx, graph, subscriptions, state, jobs, ch, &wg)
 	}
 	wg.Add(batches)
 
@@ -146,20 +238,33 @@ func (sc AprlScanner) Scan(ctx context.Context, cred azcore.TokenCredential, ser
 			j = len(rules)
 		}
 
-		jobs <- rules[i:j]
+		ids := make([]string, 0, j-i)
+		for _, r := range rules[i:j] {
+			ids = append(ids, r.RecommendationID)
+		}
+		unit := fmt.Sprintf("aprl/%s", checkpoint.Key(append(ids, subscriptionIDs...)...))
+
+		completed := []azqr.AprlResult{}
+		if state.Load(unit, &completed) {
+			ch <- aprlBatchResult{results: completed}
+			wg.Done()
+			continue
+		}
 
-		// Staggering queries to avoid throttling. Max 15 queries each 5 seconds.
-		// https://learn.microsoft.com/en-us/azure/governance/resource-graph/concepts/guidance-for-throttled-requests#staggering-queries
-		time.Sleep(5 * time.Second)
+		jobs <- aprlBatch{unit: unit, rules: rules[i:j]}
 	}
 
 	// Wait for all workers to finish
 	close(jobs)
 	wg.Wait()
 
+	errs := []error{}
 	for i := 0; i < batches; i++ {
 		res := <-ch
-		for _, r := range res {
+		if res.err != nil {
+			errs = append(errs, res.err)
+		}
+		for _, r := range res.results {
 			if filters.Azqr.IsServiceExcluded(r.ResourceID) {
 				continue
 			}
@@ -167,16 +272,30 @@ func (sc AprlScanner) Scan(ctx context.Context, cred azcore.TokenCredential, ser
 		}
 	}
 
-	return recommendations, results
+	return recommendations, results, errors.Join(errs...)
 }
 
-func (sc *AprlScanner) worker(ctx context.Context, graph *graph.GraphQuery, subscriptions map[string]string, jobs <-chan []azqr.AprlRecommendation, results chan<- []azqr.AprlResult, wg *sync.WaitGroup) {
-	for r := range jobs {
-		res, err := sc.graphScan(ctx, graph, r, subscriptions)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to scan")
+type (
+	// aprlBatch holds a batch of APRL rules and the checkpoint unit that records its completion
+	aprlBatch struct {
+		unit  string
+		rules []azqr.AprlRecommendation
+	}
+
+	// aprlBatchResult holds the results of a batch of APRL rules and the error of any query that failed
+	aprlBatchResult struct {
+		results []azqr.AprlResult
+		err     error
+	}
+)
+
+func (sc *AprlScanner) worker(ctx context.Context, graph *graph.GraphQuery, subscriptions map[string]string, state *checkpoint.Store, jobs <-chan aprlBatch, results chan<- aprlBatchResult, wg *sync.WaitGroup) {
+	for b := range jobs {
+		res, err := sc.graphScan(ctx, graph, b.rules, subscriptions)
+		if err == nil {
+			state.Save(b.unit, res)
 		}
-		results <- res
+		results <- aprlBatchResult{results: res, err: err}
 		wg.Done()
 	}
 }
@@ -188,57 +307,83 @@ func (sc AprlScanner) graphScan(ctx context.Context, graphClient *graph.GraphQue
 		subs = append(subs, &s)
 	}
 
-	sentQueries := 0
+	errs := []error{}
+	if sc.BatchQueries {
+		queries := []graph.BatchQuery{}
+		for _, rule := range rules {
+			if rule.GraphQuery != "" {
+				queries = append(queries, graph.BatchQuery{
+					ID:           rule.RecommendationID,
+					ResourceType: rule.ResourceType,
+					Query:        rule.GraphQuery,
+				})
+			}
+		}
+
+		batch := graphClient.QueryBatch(ctx, queries, subs)
+		for _, rule := range rules {
+			if err, ok := batch.Errors[rule.RecommendationID]; ok {
+				errs = append(errs, fmt.Errorf("recommendation %s: %w", rule.RecommendationID, err))
+				continue
+			}
+			results = append(results, sc.toAprlResults(rule, batch.Data[rule.RecommendationID], subscriptions)...)
+		}
+		return results, errors.Join(errs...)
+	}
+
 	for _, rule := range rules {
 		if rule.GraphQuery != "" {
-			result := graphClient.Query(ctx, rule.GraphQuery, subs)
-			if result.Data != nil {
-				for _, row := range result.Data {
-					m := row.(map[string]interface{})
-
-					log.Debug().Msg(rule.GraphQuery)
-
-					subscription := azqr.GetSubsctiptionFromResourceID(m["id"].(string))
-					subscriptionName, ok := subscriptions[subscription]
-					if !ok {
-						subscriptionName = ""
-					}
-
-					results = append(results, azqr.AprlResult{
-						RecommendationID:    rule.RecommendationID,
-						Category:            azqr.RecommendationCategory(rule.Category),
-						Recommendation:      rule.Recommendation,
-						ResourceType:        rule.ResourceType,
-						LongDescription:     rule.LongDescription,
-						PotentialBenefits:   rule.PotentialBenefits,
-						Impact:              azqr.RecommendationImpact(rule.Impact),
-						Name:                convertInterfaceToString(m["name"]),
-						ResourceID:          convertInterfaceToString(m["id"]),
-						SubscriptionID:      subscription,
-						SubscriptionName:    subscriptionName,
-						ResourceGroup:       azqr.GetResourceGroupFromResourceID(m["id"].(string)),
-						Tags:                convertInterfaceToString(m["tags"]),
-						Param1:              convertInterfaceToString(m["param1"]),
-						Param2:              convertInterfaceToString(m["param2"]),
-						Param3:              convertInterfaceToString(m["param3"]),
-						Param4:              convertInterfaceToString(m["param4"]),
-						Param5:              convertInterfaceToString(m["param5"]),
-						Learn:               rule.LearnMoreLink[0].Url,
-						AutomationAvailable: rule.AutomationAvailable,
-						Source:              "APRL",
-					})
-				}
-			}
-			sentQueries++
-			if sentQueries == 2 {
-				// Staggering queries to avoid throttling. Max 10 queries each 5 seconds.
-				// https://learn.microsoft.com/en-us/azure/governance/resource-graph/concepts/guidance-for-throttled-requests#staggering-queries
-				time.Sleep(1 * time.Second)
+			result, err := graphClient.Query(ctx, rule.GraphQuery, subs)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("recommendation %s: %w", rule.RecommendationID, err))
+			} else if result.Data != nil {
+				results = append(results, sc.toAprlResults(rule, result.Data, subscriptions)...)
 			}
 		}
 	}
 
-	return results, nil
+	return results, errors.Join(errs...)
+}
+
+// toAprlResults converts the rows returned by the query of a rule to APRL results
+func (sc AprlScanner) toAprlResults(rule azqr.AprlRecommendation, rows []interface{}, subscriptions map[string]string) []azqr.AprlResult {
+	results := []azqr.AprlResult{}
+	for _, row := range rows {
+		m := row.(map[string]interface{})
+
+		log.Debug().Msg(rule.GraphQuery)
+
+		subscription := azqr.GetSubsctiptionFromResourceID(m["id"].(string))
+		subscriptionName, ok := subscriptions[subscription]
+		if !ok {
+			subscriptionName = ""
+		}
+
+		results = append(results, azqr.AprlResult{
+			RecommendationID:    rule.RecommendationID,
+			Category:            azqr.RecommendationCategory(rule.Category),
+			Recommendation:      rule.Recommendation,
+			ResourceType:        rule.ResourceType,
+			LongDescription:     rule.LongDescription,
+			PotentialBenefits:   rule.PotentialBenefits,
+			Impact:              azqr.RecommendationImpact(rule.Impact),
+			Name:                convertInterfaceToString(m["name"]),
+			ResourceID:          convertInterfaceToString(m["id"]),
+			SubscriptionID:      subscription,
+			SubscriptionName:    subscriptionName,
+			ResourceGroup:       azqr.GetResourceGroupFromResourceID(m["id"].(string)),
+			Tags:                convertInterfaceToString(m["tags"]),
+			Param1:              convertInterfaceToString(m["param1"]),
+			Param2:              convertInterfaceToString(m["param2"]),
+			Param3:              convertInterfaceToString(m["param3"]),
+			Param4:              convertInterfaceToString(m["param4"]),
+			Param5:              convertInterfaceToString(m["param5"]),
+			Learn:               rule.LearnMoreLink[0].Url,
+			AutomationAvailable: rule.AutomationAvailable,
+			Source:              rule.Source,
+		})
+	}
+	return results
 }
 
 func (sc AprlScanner) getGraphRules(service string, filters *azqr.Filters, aprl map[string]map[string]azqr.AprlRecommendation) map[string]azqr.AprlRecommendation {
@@ -273,7 +418,8 @@ func convertInterfaceToString(i interface{}) string {
 	default:
 		jsonStr, err := json.Marshal(i)
 		if err != nil {
-			log.Fatal().Err(err).Msg("unsupported type found in ARG query result")
+			log.Warn().Err(err).Msg("unsupported type found in ARG query result")
+			return fmt.Sprintf("%v", i)
 		}
 		return string(jsonStr)
 	}
@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+)
+
+const customAprlRecommendations = `
+- description: Storage accounts must use the corporate naming convention
+  aprlGuid: org-st-001
+  recommendationTypeId: null
+  recommendationControl: Governance
+  recommendationImpact: High
+  recommendationResourceType: Microsoft.Storage/storageAccounts
+  recommendationMetadataState: Active
+  longDescription: Storage accounts must be named after the workload
+  potentialBenefits: Easier operations
+  pgVerified: false
+  publishedToLearn: false
+  publishedToAdvisor: false
+  automationAvailable: arg
+  tags: null
+  learnMoreLink:
+    - name: Naming convention
+      url: "https://contoso.com/naming"
+- description: Storage accounts must be reviewed manually
+  aprlGuid: org-st-002
+  recommendationControl: Governance
+  recommendationImpact: Low
+  recommendationResourceType: Microsoft.Storage/storageAccounts
+  learnMoreLink:
+    - name: Review
+      url: "https://contoso.com/review"
+`
+
+func writeAprlRules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestAprlScanner_GetAprlRecommendations(t *testing.T) {
+	dir := writeAprlRules(t, map[string]string{
+		"storage/recommendations.yaml": customAprlRecommendations,
+		"storage/kql/org-st-001.kql":   "resources | where type =~ 'microsoft.storage/storageaccounts' | where name !startswith 'st' | project recommendationId = 'org-st-001', name, id, tags",
+		"storage/kql/org-st-002.kql":   "// cannot-be-validated-with-arg",
+	})
+
+	aprl, err := AprlScanner{RulesDir: dir}.GetAprlRecommendations()
+	if err != nil {
+		t.Fatalf("AprlScanner.GetAprlRecommendations() error = %v", err)
+	}
+
+	r, ok := aprl["microsoft.storage/storageaccounts"]["org-st-001"]
+	if !ok {
+		t.Fatalf("AprlScanner.GetAprlRecommendations() didn't load the custom recommendation")
+	}
+	if r.Source != "CUSTOM" || !strings.Contains(r.GraphQuery, "org-st-001") {
+		t.Errorf("AprlScanner.GetAprlRecommendations() = %+v, want CUSTOM source and query", r)
+	}
+
+	// custom recommendations are skipped like the APRL ones
+	filters, _ := azqr.LoadFilters("")
+	rules := AprlScanner{}.getGraphRules("Microsoft.Storage/storageAccounts", filters, aprl)
+	if _, ok := rules["org-st-001"]; !ok {
+		t.Errorf("AprlScanner.getGraphRules() didn't return the custom recommendation")
+	}
+	if _, ok := rules["org-st-002"]; ok {
+		t.Errorf("AprlScanner.getGraphRules() returned a recommendation that cannot be validated with ARG")
+	}
+
+	rows := []interface{}{map[string]interface{}{
+		"id":   "/subscriptions/s1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/data",
+		"name": "data",
+		"tags": map[string]interface{}{"env": "prod"},
+	}}
+	results := AprlScanner{}.toAprlResults(r, rows, map[string]string{"s1": "Subscription 1"})
+	if len(results) != 1 || results[0].Source != "CUSTOM" || results[0].SubscriptionName != "Subscription 1" || results[0].Learn != "https://contoso.com/naming" {
+		t.Errorf("AprlScanner.toAprlResults() = %+v", results)
+	}
+
+	// without a directory only the APRL recommendations are loaded
+	aprl, err = AprlScanner{}.GetAprlRecommendations()
+	if err != nil {
+		t.Fatalf("AprlScanner.GetAprlRecommendations() error = %v", err)
+	}
+	for _, rt := range aprl {
+		for id, r := range rt {
+			if r.Source != "APRL" {
+				t.Errorf("AprlScanner.GetAprlRecommendations() %s source = %s, want APRL", id, r.Source)
+			}
+		}
+	}
+}
+
+func TestAprlScanner_GetAprlRecommendations_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "invalid yaml",
+			files:   map[string]string{"rules.yaml": "- aprlGuid: [org"},
+			wantErr: "rules.yaml",
+		},
+		{
+			name:    "missing id",
+			files:   map[string]string{"rules.yaml": "- recommendationResourceType: Microsoft.Storage/storageAccounts\n  learnMoreLink: [{name: a, url: b}]"},
+			wantErr: "aprlGuid is required",
+		},
+		{
+			name:    "missing resource type",
+			files:   map[string]string{"rules.yaml": "- aprlGuid: org-1\n  learnMoreLink: [{name: a, url: b}]"},
+			wantErr: "recommendationResourceType is required",
+		},
+		{
+			name:    "missing learn more link",
+			files:   map[string]string{"rules.yaml": "- aprlGuid: org-1\n  recommendationResourceType: Microsoft.Storage/storageAccounts"},
+			wantErr: "learnMoreLink is required",
+		},
+		{
+			name: "duplicate id",
+			files: map[string]string{
+				"a.yaml": customAprlRecommendations,
+				"b.yaml": customAprlRecommendations,
+			},
+			wantErr: "defined more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AprlScanner{RulesDir: writeAprlRules(t, tt.files)}.GetAprlRecommendations()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AprlScanner.GetAprlRecommendations() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("conflicts with APRL", func(t *testing.T) {
+		aprl, err := AprlScanner{}.GetAprlRecommendations()
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := ""
+		for _, rt := range aprl {
+			for i := range rt {
+				id = i
+			}
+		}
+		if id == "" {
+			t.Skip("no APRL recommendations embedded")
+		}
+
+		dir := writeAprlRules(t, map[string]string{"rules.yaml": strings.Replace(customAprlRecommendations, "org-st-001", id, 1)})
+		_, err = AprlScanner{RulesDir: dir}.GetAprlRecommendations()
+		if err == nil || !strings.Contains(err.Error(), "conflicts with an APRL recommendation") {
+			t.Errorf("AprlScanner.GetAprlRecommendations() error = %v, want conflict", err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := (AprlScanner{RulesDir: filepath.Join(t.TempDir(), "missing")}).GetAprlRecommendations(); err == nil {
+			t.Errorf("AprlScanner.GetAprlRecommendations() error = nil for a missing directory")
+		}
+	})
+}
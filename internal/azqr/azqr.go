@@ -29,7 +29,9 @@ type (
 		SubscriptionName string
 	}
 
-	// ScanContext - Struct for Scanner Context
+	// ScanContext - Struct for Scanner Context.
+	// The lookups are shared by all scanners running concurrently and must be treated as read-only.
+	// Resource specific data is only set on the per-resource copies returned by ForResource.
 	ScanContext struct {
 		Filters               *Filters
 		PrivateEndpoints      map[string]bool
@@ -92,6 +94,7 @@ type (
 		SkuTier        string
 		Kind           string
 		SLA            string
+		Tags           map[string]string
 	}
 
 	ResourceTypeCount struct {
@@ -119,6 +122,7 @@ type (
 		AutomationAvailable string `yaml:"automationAvailable"`
 		Tags                string `yaml:"tags,omitempty"`
 		GraphQuery          string `yaml:"graphQuery,omitempty"`
+		Source              string `yaml:"-"`
 		LearnMoreLink       []struct {
 			Name string `yaml:"name"`
 			Url  string `yaml:"url"`
@@ -149,6 +153,34 @@ type (
 		Source              string
 	}
 
+	// SuppressedResult - Finding moved out of the report by a suppression of the filters
+	SuppressedResult struct {
+		Source           string                 `json:"source"`
+		RecommendationID string                 `json:"recommendationId"`
+		Recommendation   string                 `json:"recommendation"`
+		Category         RecommendationCategory `json:"category"`
+		Impact           RecommendationImpact   `json:"impact"`
+		ResourceType     string                 `json:"resourceType"`
+		SubscriptionID   string                 `json:"subscriptionId"`
+		SubscriptionName string                 `json:"subscriptionName"`
+		ResourceGroup    string                 `json:"resourceGroup"`
+		Name             string                 `json:"name"`
+		ResourceID       string                 `json:"resourceId"`
+		Justification    string                 `json:"justification"`
+		Owner            string                 `json:"owner"`
+		Expires          string                 `json:"expires"`
+		// Tag is the opt-out tag of the resource that suppressed the finding, as <name>=<value>
+		Tag string `json:"tag,omitempty"`
+	}
+
+	// ScanError - Failure of a scan component for a subscription
+	ScanError struct {
+		SubscriptionID   string `json:"subscriptionId"`
+		SubscriptionName string `json:"subscriptionName"`
+		Component        string `json:"component"`
+		Message          string `json:"message"`
+	}
+
 	RecommendationEngine struct{}
 
 	RecommendationImpact   string
@@ -173,6 +205,33 @@ const (
 	TypeSLA            RecommendationType = "SLA"
 )
 
+// ParseImpact - Returns the impact with the given name, ignoring case
+func ParseImpact(name string) (RecommendationImpact, error) {
+	impact, ok := impacts[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("invalid impact %s. Use High, Medium or Low", name)
+	}
+	return impact, nil
+}
+
+// AtLeast - Returns true if the impact is the same as or higher than the given impact
+func (i RecommendationImpact) AtLeast(impact RecommendationImpact) bool {
+	return impactRank(i) >= impactRank(impact)
+}
+
+func impactRank(impact RecommendationImpact) int {
+	switch impacts[strings.ToLower(string(impact))] {
+	case ImpactHigh:
+		return 3
+	case ImpactMedium:
+		return 2
+	case ImpactLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
 func (r *AzqrRecommendation) ToAzureAprlRecommendation() AprlRecommendation {
 	return AprlRecommendation{
 		RecommendationID:    r.RecommendationID,
@@ -196,6 +255,15 @@ func (r *AzqrRecommendation) ToAzureAprlRecommendation() AprlRecommendation {
 	}
 }
 
+// ForResource - Returns a copy of the scan context for the evaluation of a single resource.
+// The copy shares the read-only lookups and has no resource specific data, so the data of the resource
+// can be attached to it without affecting other scanners or leaking into the evaluation of other resources.
+func (sc *ScanContext) ForResource() *ScanContext {
+	c := *sc
+	c.SiteConfig, c.BlobServiceProperties = nil, nil
+	return &c
+}
+
 func (e *RecommendationEngine) EvaluateRecommendations(rules map[string]AzqrRecommendation, target interface{}, scanContext *ScanContext) map[string]AzqrResult {
 	results := map[string]AzqrResult{}
 
@@ -203,6 +271,18 @@ func (e *RecommendationEngine) EvaluateRecommendations(rules map[string]AzqrReco
 		results[k] = e.evaluateRecommendation(rule, target, scanContext)
 	}
 
+	// evaluate the custom rules of the same resource type against the ARM JSON of the target
+	if custom := customRulesFor(rules); len(custom) > 0 {
+		resource, err := toARMJson(target)
+		for k, rule := range custom {
+			if err != nil {
+				results[k] = e.evaluateRecommendation(rule, target, scanContext)
+				continue
+			}
+			results[k] = e.evaluateRecommendation(rule, resource, scanContext)
+		}
+	}
+
 	return results
 }
 
@@ -221,6 +301,40 @@ func (e *RecommendationEngine) evaluateRecommendation(rule AzqrRecommendation, t
 	}
 }
 
+// NewScanError - Creates a ScanError for the given component
+func NewScanError(subscriptionID, subscriptionName, component string, err error) *ScanError {
+	return &ScanError{
+		SubscriptionID:   subscriptionID,
+		SubscriptionName: subscriptionName,
+		Component:        component,
+		Message:          err.Error(),
+	}
+}
+
+func (e *ScanError) Error() string {
+	if e.SubscriptionID == "" {
+		return fmt.Sprintf("%s: %s", e.Component, e.Message)
+	}
+	return fmt.Sprintf("subscriptions/%s %s: %s", e.SubscriptionID, e.Component, e.Message)
+}
+
+// OptOut - Returns the value of the opt-out tag of the resource if it lists the recommendation or is *.
+// The value is a comma-separated list of recommendation ids.
+func (r *Resource) OptOut(tag, recommendationID string) (string, bool) {
+	for k, v := range r.Tags {
+		if !strings.EqualFold(k, tag) {
+			continue
+		}
+		for _, id := range strings.Split(v, ",") {
+			id = strings.TrimSpace(id)
+			if id == "*" || strings.EqualFold(id, recommendationID) {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
 func (r *AzqrServiceResult) ResourceID() string {
 	return strings.ToLower(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", r.SubscriptionID, r.ResourceGroup, r.Type, r.ServiceName))
 }
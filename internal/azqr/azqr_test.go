@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseImpact(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RecommendationImpact
+		wantErr bool
+	}{
+		{name: "High", want: ImpactHigh},
+		{name: "medium", want: ImpactMedium},
+		{name: "LOW", want: ImpactLow},
+		{name: "Critical", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImpact(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImpact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseImpact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendationImpact_AtLeast(t *testing.T) {
+	tests := []struct {
+		impact    RecommendationImpact
+		threshold RecommendationImpact
+		want      bool
+	}{
+		{impact: ImpactHigh, threshold: ImpactHigh, want: true},
+		{impact: ImpactHigh, threshold: ImpactLow, want: true},
+		{impact: ImpactMedium, threshold: ImpactHigh, want: false},
+		{impact: ImpactMedium, threshold: ImpactMedium, want: true},
+		{impact: ImpactLow, threshold: ImpactMedium, want: false},
+		{impact: "high", threshold: ImpactHigh, want: true},
+		{impact: "", threshold: ImpactLow, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.impact.AtLeast(tt.threshold); got != tt.want {
+			t.Errorf("RecommendationImpact(%q).AtLeast(%q) = %v, want %v", tt.impact, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestResource_OptOut(t *testing.T) {
+	r := &Resource{Tags: map[string]string{"AZQR-IGNORE": "st-001, ST-002", "other": "*"}}
+	tests := []struct {
+		tag, id string
+		want    bool
+	}{
+		{"azqr-ignore", "st-001", true},
+		{"azqr-ignore", "st-002", true},
+		{"azqr-ignore", "st-003", false},
+		{"other", "st-003", true},
+		{"missing", "st-001", false},
+	}
+	for _, tt := range tests {
+		value, got := r.OptOut(tt.tag, tt.id)
+		if got != tt.want {
+			t.Errorf("OptOut(%s, %s) = %v, want %v", tt.tag, tt.id, got, tt.want)
+		}
+		if got && value != r.Tags[strings.ToUpper(tt.tag)] && value != r.Tags[tt.tag] {
+			t.Errorf("OptOut(%s, %s) value = %s", tt.tag, tt.id, value)
+		}
+	}
+}
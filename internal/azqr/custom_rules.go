@@ -0,0 +1,257 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// CustomRule - Rule loaded from a YAML file. The expression is written in CEL (https://cel.dev)
+	// and is evaluated against the ARM JSON of the resource, available as the resource variable.
+	// It must return true when the resource is compliant.
+	CustomRule struct {
+		RecommendationID string `yaml:"recommendationId"`
+		ResourceType     string `yaml:"resourceType"`
+		Recommendation   string `yaml:"recommendation"`
+		Category         string `yaml:"category"`
+		Impact           string `yaml:"impact"`
+		LearnMoreUrl     string `yaml:"learnMoreUrl"`
+		Expression       string `yaml:"expression"`
+	}
+)
+
+// customRules holds the custom rules by resource type. It's set before the scan starts and only read afterwards.
+var customRules = map[string]map[string]AzqrRecommendation{}
+
+var categories = map[string]RecommendationCategory{
+	strings.ToLower(string(CategoryHighAvailability)):      CategoryHighAvailability,
+	strings.ToLower(string(CategoryMonitoringAndAlerting)): CategoryMonitoringAndAlerting,
+	strings.ToLower(string(CategoryScalability)):           CategoryScalability,
+	strings.ToLower(string(CategoryDisasterRecovery)):      CategoryDisasterRecovery,
+	strings.ToLower(string(CategorySecurity)):              CategorySecurity,
+	strings.ToLower(string(CategoryGovernance)):            CategoryGovernance,
+	strings.ToLower(string(CategoryOtherBestPractices)):    CategoryOtherBestPractices,
+}
+
+var impacts = map[string]RecommendationImpact{
+	strings.ToLower(string(ImpactHigh)):   ImpactHigh,
+	strings.ToLower(string(ImpactMedium)): ImpactMedium,
+	strings.ToLower(string(ImpactLow)):    ImpactLow,
+}
+
+// LoadCustomRules - Loads the custom rules of all the YAML files in a directory and registers them,
+// so they are evaluated along with the built-in rules of the scanner of their resource type
+func LoadCustomRules(dir string, scanners []IAzureScanner) error {
+	if dir == "" {
+		return nil
+	}
+
+	rules, err := ReadCustomRules(dir, scanners)
+	if err != nil {
+		return err
+	}
+
+	customRules = map[string]map[string]AzqrRecommendation{}
+	for _, r := range rules {
+		t := strings.ToLower(r.ResourceType)
+		if customRules[t] == nil {
+			customRules[t] = map[string]AzqrRecommendation{}
+		}
+		customRules[t][r.RecommendationID] = r
+	}
+
+	log.Info().Msgf("Loaded %d custom rules from %s", len(rules), dir)
+	return nil
+}
+
+// ReadCustomRules - Reads and compiles the custom rules of all the YAML files in a directory.
+// Rules reusing the id of a built-in rule of the scanners are rejected.
+func ReadCustomRules(dir string, scanners []IAzureScanner) ([]AzqrRecommendation, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	ymlFiles, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, ymlFiles...)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no rule files found in %s", dir)
+	}
+
+	env, err := cel.NewEnv(cel.Variable("resource", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+
+	builtIn := map[string]bool{}
+	for _, s := range scanners {
+		for k := range s.GetRecommendations() {
+			builtIn[strings.ToLower(k)] = true
+		}
+	}
+
+	ids := map[string]string{}
+	rules := []AzqrRecommendation{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var customRules []CustomRule
+		if err := yaml.Unmarshal(content, &customRules); err != nil {
+			return nil, fmt.Errorf("failed parsing rules file %s: %w", file, err)
+		}
+
+		for _, cr := range customRules {
+			if builtIn[strings.ToLower(cr.RecommendationID)] {
+				return nil, fmt.Errorf("rule %s in %s conflicts with a built-in rule", cr.RecommendationID, file)
+			}
+			if f, ok := ids[strings.ToLower(cr.RecommendationID)]; ok {
+				return nil, fmt.Errorf("rule %s in %s is already defined in %s", cr.RecommendationID, file, f)
+			}
+			ids[strings.ToLower(cr.RecommendationID)] = file
+
+			r, err := cr.compile(env)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule in %s: %w", file, err)
+			}
+			rules = append(rules, r)
+		}
+	}
+
+	return rules, nil
+}
+
+// compile validates the custom rule and returns the recommendation that evaluates its expression
+func (cr *CustomRule) compile(env *cel.Env) (AzqrRecommendation, error) {
+	if cr.RecommendationID == "" {
+		return AzqrRecommendation{}, errors.New("recommendationId is required")
+	}
+	if cr.ResourceType == "" {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: resourceType is required", cr.RecommendationID)
+	}
+	if cr.Expression == "" {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: expression is required", cr.RecommendationID)
+	}
+
+	category, ok := categories[strings.ToLower(cr.Category)]
+	if !ok {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: unknown category %q", cr.RecommendationID, cr.Category)
+	}
+
+	impact, ok := impacts[strings.ToLower(cr.Impact)]
+	if !ok {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: unknown impact %q", cr.RecommendationID, cr.Impact)
+	}
+
+	ast, iss := env.Compile(cr.Expression)
+	if iss.Err() != nil {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: %w", cr.RecommendationID, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: expression must return a bool, not %s", cr.RecommendationID, ast.OutputType())
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return AzqrRecommendation{}, fmt.Errorf("rule %s: %w", cr.RecommendationID, err)
+	}
+
+	return AzqrRecommendation{
+		RecommendationID: cr.RecommendationID,
+		ResourceType:     cr.ResourceType,
+		Recommendation:   cr.Recommendation,
+		Category:         category,
+		Impact:           impact,
+		LearnMoreUrl:     cr.LearnMoreUrl,
+		Eval: func(target interface{}, scanContext *ScanContext) (bool, string) {
+			// the engine converts the target once for all the custom rules of the resource
+			resource, ok := target.(map[string]interface{})
+			if !ok {
+				var err error
+				if resource, err = toARMJson(target); err != nil {
+					return true, err.Error()
+				}
+			}
+
+			out, _, err := prg.Eval(map[string]interface{}{"resource": resource})
+			if err != nil {
+				return true, fmt.Sprintf("expression failed: %s", err)
+			}
+
+			compliant, ok := out.Value().(bool)
+			if !ok {
+				return true, fmt.Sprintf("expression returned %v instead of a bool", out.Value())
+			}
+			return !compliant, ""
+		},
+	}, nil
+}
+
+// GetRecommendations - Returns the built-in recommendations of a scanner merged with the custom rules of its resource types
+func GetRecommendations(s IAzureScanner) map[string]AzqrRecommendation {
+	recommendations := s.GetRecommendations()
+	if len(customRules) == 0 {
+		return recommendations
+	}
+
+	merged := map[string]AzqrRecommendation{}
+	for k, r := range recommendations {
+		merged[k] = r
+	}
+	for _, t := range s.ResourceTypes() {
+		for k, r := range customRules[strings.ToLower(t)] {
+			merged[k] = r
+		}
+	}
+	return merged
+}
+
+// customRulesFor returns the custom rules of the resource types of a set of built-in rules
+func customRulesFor(rules map[string]AzqrRecommendation) map[string]AzqrRecommendation {
+	if len(customRules) == 0 {
+		return nil
+	}
+
+	types := map[string]bool{}
+	for _, r := range rules {
+		types[strings.ToLower(r.ResourceType)] = true
+	}
+
+	result := map[string]AzqrRecommendation{}
+	for t := range types {
+		for k, r := range customRules[t] {
+			result[k] = r
+		}
+	}
+	return result
+}
+
+// toARMJson converts an SDK model to its ARM JSON representation
+func toARMJson(target interface{}) (map[string]interface{}, error) {
+	js, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert resource to json: %w", err)
+	}
+
+	resource := map[string]interface{}{}
+	if err := json.Unmarshal(js, &resource); err != nil {
+		return nil, fmt.Errorf("failed to convert resource to json: %w", err)
+	}
+	return resource, nil
+}
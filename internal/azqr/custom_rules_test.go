@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+const storageRules = `
+- recommendationId: org-st-001
+  resourceType: Microsoft.Storage/storageAccounts
+  recommendation: Storage must use TLS 1.2
+  category: security
+  impact: High
+  learnMoreUrl: https://learn.microsoft.com
+  expression: resource.properties.minimumTlsVersion == "TLS1_2"
+`
+
+type fakeStorageScanner struct{}
+
+func (s *fakeStorageScanner) Init(config *ScannerConfig) error { return nil }
+
+func (s *fakeStorageScanner) Scan(scanContext *ScanContext) ([]AzqrServiceResult, error) {
+	return nil, nil
+}
+
+func (s *fakeStorageScanner) ResourceTypes() []string {
+	return []string{"Microsoft.Storage/storageAccounts"}
+}
+
+func (s *fakeStorageScanner) GetRecommendations() map[string]AzqrRecommendation {
+	return map[string]AzqrRecommendation{
+		"st-001": {
+			RecommendationID: "st-001",
+			ResourceType:     "Microsoft.Storage/storageAccounts",
+			Category:         CategoryMonitoringAndAlerting,
+			Impact:           ImpactLow,
+			Eval: func(target interface{}, scanContext *ScanContext) (bool, string) {
+				return false, ""
+			},
+		},
+	}
+}
+
+func writeRules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadCustomRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantIDs []string
+		wantErr string
+	}{
+		{
+			name:    "yaml and yml files",
+			files:   map[string]string{"st.yaml": storageRules, "aks.yml": strings.ReplaceAll(storageRules, "org-st-001", "org-st-002")},
+			wantIDs: []string{"org-st-001", "org-st-002"},
+		},
+		{
+			name:    "no rule files",
+			files:   map[string]string{"readme.md": ""},
+			wantErr: "no rule files found",
+		},
+		{
+			name:    "unknown category",
+			files:   map[string]string{"st.yaml": strings.ReplaceAll(storageRules, "category: security", "category: cost")},
+			wantErr: "unknown category",
+		},
+		{
+			name:    "unknown impact",
+			files:   map[string]string{"st.yaml": strings.ReplaceAll(storageRules, "impact: High", "impact: Critical")},
+			wantErr: "unknown impact",
+		},
+		{
+			name:    "invalid expression",
+			files:   map[string]string{"st.yaml": strings.ReplaceAll(storageRules, `== "TLS1_2"`, `== `)},
+			wantErr: "rule org-st-001",
+		},
+		{
+			name:    "expression not returning a bool",
+			files:   map[string]string{"st.yaml": strings.ReplaceAll(storageRules, `resource.properties.minimumTlsVersion == "TLS1_2"`, `size(resource.name)`)},
+			wantErr: "must return a bool",
+		},
+		{
+			name:    "duplicate ids",
+			files:   map[string]string{"a.yaml": storageRules, "b.yaml": strings.ReplaceAll(storageRules, "org-st-001", "ORG-ST-001")},
+			wantErr: "is already defined",
+		},
+		{
+			name:    "built-in id",
+			files:   map[string]string{"st.yaml": strings.ReplaceAll(storageRules, "org-st-001", "st-001")},
+			wantErr: "conflicts with a built-in rule",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ReadCustomRules(writeRules(t, tt.files), []IAzureScanner{&fakeStorageScanner{}})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadCustomRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadCustomRules() error = %v", err)
+			}
+
+			ids := []string{}
+			for _, r := range rules {
+				ids = append(ids, r.RecommendationID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("ReadCustomRules() = %v, want %v", ids, tt.wantIDs)
+			}
+			if rules[0].Category != CategorySecurity || rules[0].Impact != ImpactHigh {
+				t.Errorf("ReadCustomRules() category = %s, impact = %s", rules[0].Category, rules[0].Impact)
+			}
+		})
+	}
+}
+
+func TestCustomRules_Evaluate(t *testing.T) {
+	scanner := &fakeStorageScanner{}
+	if err := LoadCustomRules(writeRules(t, map[string]string{"st.yaml": storageRules}), []IAzureScanner{scanner}); err != nil {
+		t.Fatalf("LoadCustomRules() error = %v", err)
+	}
+	defer func() { customRules = map[string]map[string]AzqrRecommendation{} }()
+
+	tests := []struct {
+		name       string
+		target     interface{}
+		wantBroken bool
+		wantResult string
+	}{
+		{
+			name: "compliant",
+			target: &armstorage.Account{
+				Type:       to.Ptr("Microsoft.Storage/storageAccounts"),
+				Properties: &armstorage.AccountProperties{MinimumTLSVersion: to.Ptr(armstorage.MinimumTLSVersionTLS12)},
+			},
+			wantBroken: false,
+		},
+		{
+			name: "not compliant",
+			target: &armstorage.Account{
+				Type:       to.Ptr("Microsoft.Storage/storageAccounts"),
+				Properties: &armstorage.AccountProperties{MinimumTLSVersion: to.Ptr(armstorage.MinimumTLSVersionTLS10)},
+			},
+			wantBroken: true,
+		},
+		{
+			name: "missing property",
+			target: &armstorage.Account{
+				Type: to.Ptr("Microsoft.Storage/storageAccounts"),
+			},
+			wantBroken: true,
+			wantResult: "expression failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := RecommendationEngine{}
+			results := engine.EvaluateRecommendations(scanner.GetRecommendations(), tt.target, &ScanContext{})
+			if len(results) != 2 {
+				t.Fatalf("EvaluateRecommendations() returned %d results, want 2", len(results))
+			}
+			got := results["org-st-001"]
+			if got.NotCompliant != tt.wantBroken || !strings.HasPrefix(got.Result, tt.wantResult) {
+				t.Errorf("EvaluateRecommendations() = %v, %q, want %v, %q", got.NotCompliant, got.Result, tt.wantBroken, tt.wantResult)
+			}
+		})
+	}
+
+	t.Run("GetRecommendations", func(t *testing.T) {
+		got := GetRecommendations(scanner)
+		if len(got) != 2 {
+			t.Fatalf("GetRecommendations() returned %d recommendations, want 2", len(got))
+		}
+		if got["org-st-001"].Recommendation != "Storage must use TLS 1.2" || got["st-001"].Impact != ImpactLow {
+			t.Errorf("GetRecommendations() = %v", got)
+		}
+	})
+}
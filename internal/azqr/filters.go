@@ -3,13 +3,24 @@
 package azqr
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/rs/zerolog/log"
+
 	"gopkg.in/yaml.v3"
 )
 
+var subscriptionIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type (
 	Filters struct {
 		Azqr *AzqrFilter `yaml:"azqr"`
@@ -18,26 +29,49 @@ type (
 	AzqrFilter struct {
 		Include          *IncludeFilter `yaml:"include"`
 		Exclude          *ExcludeFilter `yaml:"exclude"`
+		Suppressions     []*Suppression `yaml:"suppressions"`
 		iSubscriptions   map[string]bool
 		iResourceGroups  map[string]bool
 		xSubscriptions   map[string]bool
 		xResourceGroups  map[string]bool
 		xServices        map[string]bool
 		xRecommendations map[string]bool
+		resources        map[string]*Resource
 	}
 
 	// ExcludeFilter - Struct for ExcludeFilter
 	ExcludeFilter struct {
-		Subscriptions   []string `yaml:"subscriptions,flow"`
-		ResourceGroups  []string `yaml:"resourceGroups,flow"`
-		Services        []string `yaml:"services,flow"`
-		Recommendations []string `yaml:"recommendations,flow"`
+		Subscriptions    []string `yaml:"subscriptions,flow"`
+		ResourceGroups   []string `yaml:"resourceGroups,flow"`
+		Services         []string `yaml:"services,flow"`
+		Recommendations  []string `yaml:"recommendations,flow"`
+		ResourceSelector `yaml:",inline"`
 	}
 
 	// IncludeFilter - Struct for IncludeFilter
 	IncludeFilter struct {
-		Subscriptions  []string `yaml:"subscriptions,flow"`
-		ResourceGroups []string `yaml:"resourceGroups,flow"`
+		Subscriptions    []string `yaml:"subscriptions,flow"`
+		ResourceGroups   []string `yaml:"resourceGroups,flow"`
+		ResourceSelector `yaml:",inline"`
+	}
+
+	// Suppression - Accepted finding of a recommendation, optionally scoped to a resource, resource group or subscription.
+	// Suppressed findings are reported separately until the suppression expires.
+	Suppression struct {
+		RecommendationID string `yaml:"recommendationId"`
+		Scope            string `yaml:"scope"`
+		Justification    string `yaml:"justification"`
+		Owner            string `yaml:"owner"`
+		Expires          string `yaml:"expires"`
+		expires          time.Time
+	}
+
+	// ResourceSelector - Selects resources by tag (key, key=value or key=<glob>), type, location or name (glob)
+	ResourceSelector struct {
+		Tags          []string `yaml:"tags,flow"`
+		ResourceTypes []string `yaml:"resourceTypes,flow"`
+		Locations     []string `yaml:"locations,flow"`
+		Names         []string `yaml:"names,flow"`
 	}
 )
 
@@ -67,6 +101,14 @@ func (e *AzqrFilter) IsSubscriptionExcluded(subscriptionID string) bool {
 	return ok
 }
 
+// AddResource - Adds a resource of the inventory, so its tags and location can be matched by the selectors
+func (e *AzqrFilter) AddResource(resource *Resource) {
+	if e.resources == nil {
+		e.resources = make(map[string]*Resource)
+	}
+	e.resources[strings.ToLower(resource.ID)] = resource
+}
+
 func (e *AzqrFilter) IsServiceExcluded(resourceID string) bool {
 	rgID := GetResourceGroupIDFromResourceID(resourceID)
 	ok := e.isResourceGroupExcluded(rgID)
@@ -75,15 +117,171 @@ func (e *AzqrFilter) IsServiceExcluded(resourceID string) bool {
 		_, ok = e.xServices[strings.ToLower(resourceID)]
 	}
 
+	if !ok {
+		resource, found := e.resource(resourceID)
+		ok = e.isResourceExcluded(resource, found)
+	}
+
 	return ok
 }
 
+// isResourceExcluded returns true if the resource doesn't match every include selector or matches an exclude selector.
+// The tag and location include selectors are skipped for resources that are not in the inventory, instead of excluding them.
+func (e *AzqrFilter) isResourceExcluded(resource *Resource, inInventory bool) bool {
+	include := e.Include.ResourceSelector
+	if !inInventory && (len(include.Tags) > 0 || len(include.Locations) > 0) {
+		log.Warn().Msgf("Resource %s is not in the inventory. Skipping the tag and location include selectors", resource.ID)
+		include.Tags, include.Locations = nil, nil
+	}
+
+	if !include.matchesAll(resource) {
+		return true
+	}
+	return e.Exclude.matchesAny(resource)
+}
+
+// resource returns the resource of the inventory with the given id and true if it was found. Resources that are
+// not in the inventory only have the type and name found in their id, so tag and location selectors never match them.
+func (e *AzqrFilter) resource(resourceID string) (*Resource, bool) {
+	if r, ok := e.resources[strings.ToLower(resourceID)]; ok {
+		return r, true
+	}
+
+	r := &Resource{ID: resourceID}
+	if id, err := arm.ParseResourceID(resourceID); err == nil {
+		r.Type = id.ResourceType.String()
+		r.Name = id.Name
+	}
+	return r, false
+}
+
+// matchesAll returns true if the resource matches one entry of every selector that is set
+func (s ResourceSelector) matchesAll(r *Resource) bool {
+	return (len(s.Tags) == 0 || matchTags(s.Tags, r.Tags)) &&
+		(len(s.ResourceTypes) == 0 || matchAny(s.ResourceTypes, r.Type)) &&
+		(len(s.Locations) == 0 || matchAny(s.Locations, r.Location)) &&
+		(len(s.Names) == 0 || matchAny(s.Names, r.Name))
+}
+
+// matchesAny returns true if the resource matches an entry of any selector
+func (s ResourceSelector) matchesAny(r *Resource) bool {
+	return matchTags(s.Tags, r.Tags) ||
+		matchAny(s.ResourceTypes, r.Type) ||
+		matchAny(s.Locations, r.Location) ||
+		matchAny(s.Names, r.Name)
+}
+
+// validate returns an error if a glob of the selector is malformed
+func (s ResourceSelector) validate() error {
+	patterns := append(append(append([]string{}, s.ResourceTypes...), s.Locations...), s.Names...)
+	for _, t := range s.Tags {
+		if _, value, ok := strings.Cut(t, "="); ok {
+			patterns = append(patterns, value)
+		}
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// matchTags returns true if one of the tags matches a selector: key, key=value or key=<glob>.
+// Keys are compared ignoring case, as Azure does.
+func matchTags(selectors []string, tags map[string]string) bool {
+	for _, s := range selectors {
+		key, value, hasValue := strings.Cut(s, "=")
+		for k, v := range tags {
+			if strings.EqualFold(strings.TrimSpace(key), k) && (!hasValue || match(strings.TrimSpace(value), v)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchAny returns true if the value matches one of the globs
+func matchAny(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if match(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns true if the value matches the glob, ignoring case
+func match(pattern, value string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return ok && err == nil
+}
+
+// Suppression - Returns the suppression of the recommendation that matches the resource, if any.
+// Active suppressions are returned before expired ones.
+func (e *AzqrFilter) Suppression(recommendationID, resourceID string, now time.Time) *Suppression {
+	var expired *Suppression
+	for _, s := range e.Suppressions {
+		if !s.Matches(recommendationID, resourceID) {
+			continue
+		}
+		if !s.IsExpired(now) {
+			return s
+		}
+		if expired == nil {
+			expired = s
+		}
+	}
+	return expired
+}
+
+// Matches - Returns true if the suppression applies to the recommendation on the resource
+func (s *Suppression) Matches(recommendationID, resourceID string) bool {
+	if !strings.EqualFold(s.RecommendationID, recommendationID) {
+		return false
+	}
+	scope := strings.ToLower(s.Scope)
+	id := strings.ToLower(resourceID)
+	return scope == "" || id == scope || strings.HasPrefix(id, scope+"/")
+}
+
+// IsExpired - Returns true if the suppression expired before the given time. Suppressions expire at the end of their expiry date.
+func (s *Suppression) IsExpired(now time.Time) bool {
+	return !now.Before(s.expires.AddDate(0, 0, 1))
+}
+
+// validate checks the mandatory fields and the expiry date, and turns subscription id scopes into resource ids
+func (s *Suppression) validate() error {
+	if s.RecommendationID == "" {
+		return fmt.Errorf("recommendationId is required")
+	}
+
+	for field, value := range map[string]string{"justification": s.Justification, "owner": s.Owner, "expires": s.Expires} {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s is required for recommendation %s", field, s.RecommendationID)
+		}
+	}
+
+	var err error
+	if s.expires, err = time.Parse("2006-01-02", s.Expires); err != nil {
+		return fmt.Errorf("expires of recommendation %s must use the YYYY-MM-DD format", s.RecommendationID)
+	}
+
+	if subscriptionIDRegex.MatchString(s.Scope) {
+		s.Scope = fmt.Sprintf("/subscriptions/%s", s.Scope)
+	}
+	if s.Scope != "" && !strings.HasPrefix(strings.ToLower(s.Scope), "/subscriptions/") {
+		return fmt.Errorf("scope %s of recommendation %s must be a subscription id, a resource group id or a resource id", s.Scope, s.RecommendationID)
+	}
+	return nil
+}
+
 func (e *AzqrFilter) IsRecommendationExcluded(recommendationID string) bool {
 	_, ok := e.xRecommendations[strings.ToLower(recommendationID)]
 	return ok
 }
 
-func LoadFilters(filterFile string) *Filters {
+func LoadFilters(filterFile string) (*Filters, error) {
 	filters := &Filters{
 		Azqr: &AzqrFilter{
 			Include: &IncludeFilter{
@@ -101,12 +299,38 @@ func LoadFilters(filterFile string) *Filters {
 	if filterFile != "" {
 		data, err := os.ReadFile(filterFile)
 		if err != nil {
-			log.Fatal().Err(err).Msgf("failed reading data from file: %s", filterFile)
+			return nil, fmt.Errorf("failed reading data from file %s: %w", filterFile, err)
 		}
 
-		err = yaml.Unmarshal([]byte(data), &filters)
-		if err != nil {
-			log.Fatal().Err(err).Msgf("failed parsing yaml from file: %s", filterFile)
+		// unknown keys are errors, so typos don't silently disable a filter
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(filters); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed parsing yaml from file %s: %w", filterFile, err)
+		}
+
+		// empty sections are decoded as nil
+		if filters.Azqr == nil {
+			filters.Azqr = &AzqrFilter{}
+		}
+		if filters.Azqr.Include == nil {
+			filters.Azqr.Include = &IncludeFilter{}
+		}
+		if filters.Azqr.Exclude == nil {
+			filters.Azqr.Exclude = &ExcludeFilter{}
+		}
+
+		for i, s := range filters.Azqr.Suppressions {
+			if err := s.validate(); err != nil {
+				return nil, fmt.Errorf("invalid suppression %d in file %s: %w", i+1, filterFile, err)
+			}
+		}
+
+		if err := filters.Azqr.Include.validate(); err != nil {
+			return nil, fmt.Errorf("invalid include selector in file %s: %w", filterFile, err)
+		}
+		if err := filters.Azqr.Exclude.validate(); err != nil {
+			return nil, fmt.Errorf("invalid exclude selector in file %s: %w", filterFile, err)
 		}
 	}
 
@@ -130,7 +354,7 @@ func LoadFilters(filterFile string) *Filters {
 		filters.Azqr.xRecommendations[strings.ToLower(id)] = true
 	}
 
-	return filters
+	return filters, nil
 }
 
 func (e *AzqrFilter) isResourceGroupExcluded(resourceGroupID string) bool {
@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package azqr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const filtersSubscription = "/subscriptions/00000000-0000-0000-0000-000000000001"
+
+func loadTestFilters(t *testing.T, content string) (*Filters, error) {
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadFilters(file)
+}
+
+func testResources() map[string]*Resource {
+	resources := map[string]*Resource{
+		"prod-st": {Type: "Microsoft.Storage/storageAccounts", Location: "westeurope", Tags: map[string]string{"Env": "prod", "workload": "payments"}},
+		"test-st": {Type: "Microsoft.Storage/storageAccounts", Location: "westus", Tags: map[string]string{"env": "test"}},
+		"watcher": {Type: "Microsoft.Network/networkWatchers", Location: "westeurope", Tags: map[string]string{"env": "prod-shared"}},
+		"prod-vm": {Type: "Microsoft.Compute/virtualMachines", Location: "westeurope"},
+	}
+	for name, r := range resources {
+		r.Name = name
+		r.ID = filtersSubscription + "/resourceGroups/rg/providers/" + r.Type + "/" + name
+	}
+	return resources
+}
+
+func TestAzqrFilter_Selectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  string
+		excluded []string
+	}{
+		{
+			name:     "no selectors",
+			filters:  "azqr: {}",
+			excluded: []string{},
+		},
+		{
+			name:     "exclude by type",
+			filters:  "azqr: {exclude: {resourceTypes: [microsoft.network/networkwatchers]}}",
+			excluded: []string{"watcher"},
+		},
+		{
+			name:     "exclude by location",
+			filters:  "azqr: {exclude: {locations: [westus]}}",
+			excluded: []string{"test-st"},
+		},
+		{
+			name:     "exclude by name glob",
+			filters:  "azqr: {exclude: {names: [\"*-st\"]}}",
+			excluded: []string{"prod-st", "test-st"},
+		},
+		{
+			name:     "exclude by tag key",
+			filters:  "azqr: {exclude: {tags: [workload]}}",
+			excluded: []string{"prod-st"},
+		},
+		{
+			name:     "include by tag value",
+			filters:  "azqr: {include: {tags: [env=prod]}}",
+			excluded: []string{"test-st", "watcher", "prod-vm"},
+		},
+		{
+			name:     "include by tag value glob",
+			filters:  "azqr: {include: {tags: [\"env=prod*\"]}}",
+			excluded: []string{"test-st", "prod-vm"},
+		},
+		{
+			name:     "include requires every selector",
+			filters:  "azqr: {include: {tags: [\"env=prod*\"], locations: [westeurope], resourceTypes: [\"Microsoft.Storage/*\"]}}",
+			excluded: []string{"test-st", "watcher", "prod-vm"},
+		},
+		{
+			name:     "include and exclude",
+			filters:  "azqr: {include: {locations: [westeurope]}, exclude: {tags: [\"env=prod-*\"]}}",
+			excluded: []string{"test-st", "watcher"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := loadTestFilters(t, tt.filters)
+			if err != nil {
+				t.Fatalf("LoadFilters() error = %v", err)
+			}
+
+			resources := testResources()
+			for _, r := range resources {
+				filters.Azqr.AddResource(r)
+			}
+
+			for name, r := range resources {
+				want := false
+				for _, e := range tt.excluded {
+					want = want || e == name
+				}
+				// results use lower case ids
+				if got := filters.Azqr.IsServiceExcluded(strings.ToLower(r.ID)); got != want {
+					t.Errorf("IsServiceExcluded(%s) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAzqrFilter_SelectorsWithoutInventory(t *testing.T) {
+	filters, err := loadTestFilters(t, "azqr: {exclude: {resourceTypes: [Microsoft.Network/networkWatchers], names: [\"*-vm\"], locations: [westeurope]}}")
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+
+	// resources that are not in the inventory are matched by the type and name of their id
+	resources := testResources()
+	tests := map[string]bool{"watcher": true, "prod-vm": true, "prod-st": false}
+	for name, want := range tests {
+		if got := filters.Azqr.IsServiceExcluded(resources[name].ID); got != want {
+			t.Errorf("IsServiceExcluded(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAzqrFilter_IncludeSelectorsWithoutInventory(t *testing.T) {
+	filters, err := loadTestFilters(t, "azqr: {include: {tags: [env=prod], locations: [westeurope], resourceTypes: [\"Microsoft.Storage/*\"]}}")
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+
+	resources := testResources()
+	filters.Azqr.AddResource(resources["test-st"])
+
+	// the tag and location selectors are skipped for resources that are not in the inventory, the other selectors still apply
+	tests := map[string]bool{"prod-st": false, "watcher": true, "prod-vm": true, "test-st": true}
+	for name, want := range tests {
+		if got := filters.Azqr.IsServiceExcluded(resources[name].ID); got != want {
+			t.Errorf("IsServiceExcluded(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestLoadFilters_InvalidSelector(t *testing.T) {
+	if _, err := loadTestFilters(t, "azqr: {exclude: {names: [\"[a-\"]}}"); err == nil || !strings.Contains(err.Error(), "invalid exclude selector") {
+		t.Errorf("LoadFilters() error = %v, want invalid exclude selector", err)
+	}
+	if _, err := loadTestFilters(t, "azqr: {include: {tags: [\"env=[\"]}}"); err == nil || !strings.Contains(err.Error(), "invalid include selector") {
+		t.Errorf("LoadFilters() error = %v, want invalid include selector", err)
+	}
+}
+
+func TestLoadFilters_Suppressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing justification", "{recommendationId: st-001, owner: me, expires: 2030-01-01}", "justification is required"},
+		{"missing owner", "{recommendationId: st-001, justification: ok, expires: 2030-01-01}", "owner is required"},
+		{"missing expiry", "{recommendationId: st-001, justification: ok, owner: me}", "expires is required"},
+		{"invalid expiry", "{recommendationId: st-001, justification: ok, owner: me, expires: 01/01/2030}", "YYYY-MM-DD"},
+		{"invalid scope", "{recommendationId: st-001, justification: ok, owner: me, expires: 2030-01-01, scope: rg}", "must be a subscription id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestFilters(t, "azqr: {suppressions: ["+tt.content+"]}")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFilters() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	filters, err := loadTestFilters(t, "azqr: {suppressions: [{recommendationId: st-001, justification: ok, owner: me, expires: 2030-01-01, scope: 00000000-0000-0000-0000-000000000001}]}")
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+	s := filters.Azqr.Suppressions[0]
+	if s.Scope != filtersSubscription {
+		t.Errorf("LoadFilters() scope = %s, want %s", s.Scope, filtersSubscription)
+	}
+	if !s.Matches("ST-001", filtersSubscription+"/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st") {
+		t.Errorf("Matches() = false, want true")
+	}
+	if s.Matches("st-001", "/subscriptions/00000000-0000-0000-0000-000000000002") {
+		t.Errorf("Matches() = true, want false")
+	}
+	if s.IsExpired(time.Date(2030, 1, 1, 23, 59, 0, 0, time.UTC)) || !s.IsExpired(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("IsExpired() doesn't expire at the end of the expiry date")
+	}
+}
+
+func TestLoadFilters_IncludedAndExcluded(t *testing.T) {
+	rg := filtersSubscription + "/resourceGroups/rg"
+	filters, err := loadTestFilters(t, "azqr: {include: {subscriptions: [00000000-0000-0000-0000-000000000001], resourceGroups: ["+rg+"]}, exclude: {subscriptions: [00000000-0000-0000-0000-000000000001], resourceGroups: ["+rg+"]}}")
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+
+	// the include lists of the file don't override the exclude lists, so the entries are not scanned
+	if !filters.Azqr.IsSubscriptionExcluded("00000000-0000-0000-0000-000000000001") {
+		t.Errorf("IsSubscriptionExcluded() = false, want true")
+	}
+	if !filters.Azqr.isResourceGroupExcluded(rg) {
+		t.Errorf("isResourceGroupExcluded() = false, want true")
+	}
+	if !filters.Azqr.IsServiceExcluded(rg + "/providers/Microsoft.Storage/storageAccounts/st") {
+		t.Errorf("IsServiceExcluded() = false, want true")
+	}
+}
+
+func TestLoadFilters_NullAzqr(t *testing.T) {
+	for _, content := range []string{"azqr:", "azqr: ~", "azqr: null"} {
+		filters, err := loadTestFilters(t, content)
+		if err != nil {
+			t.Fatalf("LoadFilters(%q) error = %v", content, err)
+		}
+		if filters.Azqr.IsSubscriptionExcluded("00000000-0000-0000-0000-000000000001") || filters.Azqr.IsServiceExcluded(filtersSubscription+"/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st") {
+			t.Errorf("LoadFilters(%q) excludes resources, want no filters", content)
+		}
+	}
+}
+
+func TestLoadFilters_UnknownKey(t *testing.T) {
+	if _, err := loadTestFilters(t, "azqr: {exclude: {recomendations: [st-001]}}"); err == nil || !strings.Contains(err.Error(), "field recomendations not found") {
+		t.Errorf("LoadFilters() error = %v, want unknown field", err)
+	}
+	if _, err := loadTestFilters(t, ""); err != nil {
+		t.Errorf("LoadFilters() of an empty file error = %v", err)
+	}
+}
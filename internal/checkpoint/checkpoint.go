@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package checkpoint
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// manifestFile - File of the state directory with the options of the scan that created it
+const manifestFile = "manifest.json"
+
+type (
+	// Store - Persists the results of completed scan units to a state directory, so an interrupted scan can be resumed
+	Store struct {
+		dir    string
+		resume bool
+	}
+)
+
+// NewStore - Creates a checkpoint store in the given directory. An empty directory disables checkpointing.
+// The options that change the results of the scan are saved in the directory, and a scan is only resumed
+// with the same options, so units scanned with other options are never merged into the report.
+func NewStore(dir string, resume bool, options interface{}) (*Store, error) {
+	if dir == "" {
+		return &Store{}, nil
+	}
+
+	manifest, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scan options: %w", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read state directory %s: %w", dir, err)
+	}
+
+	if resume {
+		if saved == nil {
+			return nil, fmt.Errorf("state directory %s does not contain a scan to resume", dir)
+		}
+		if !bytes.Equal(bytes.TrimSpace(saved), manifest) {
+			return nil, fmt.Errorf("the scan in state directory %s was run with different options (%s). Use the same flags to resume it", dir, saved)
+		}
+		log.Info().Msgf("Resuming scan from state directory: %s", dir)
+		return &Store{dir: dir, resume: resume}, nil
+	}
+
+	if saved != nil {
+		return nil, fmt.Errorf("state directory %s already contains a scan. Use --resume to continue it, or use another directory", dir)
+	}
+
+	s := &Store{dir: dir}
+	if err := s.write(filepath.Join(dir, manifestFile), options); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+	return s, nil
+}
+
+// Load - Loads the results of a completed unit into v. Returns false if the unit must be scanned.
+func (s *Store) Load(unit string, v interface{}) bool {
+	if s.dir == "" || !s.resume {
+		return false
+	}
+
+	content, err := os.ReadFile(s.path(unit))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn().Err(err).Msgf("Failed to read checkpoint %s. Scanning it again...", unit)
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(content, v); err != nil {
+		log.Warn().Err(err).Msgf("Failed to parse checkpoint %s. Scanning it again...", unit)
+		return false
+	}
+
+	log.Debug().Msgf("Skipping completed unit: %s", unit)
+	return true
+}
+
+// Save - Records a unit as completed along with its results.
+// Failing to save a checkpoint does not stop the scan, the unit will just be scanned again on resume.
+func (s *Store) Save(unit string, v interface{}) {
+	if s.dir == "" {
+		return
+	}
+
+	path := s.path(unit)
+	if err := s.write(path, v); err != nil {
+		log.Warn().Err(err).Msgf("Failed to save checkpoint %s", unit)
+	}
+}
+
+// write marshals v to a temporary file and renames it, so a crash never leaves a partial checkpoint behind
+func (s *Store) write(path string, v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, js, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) path(unit string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(unit)+".json")
+}
+
+// Key - Returns a stable key for a set of values, regardless of their order and case
+func Key(values ...string) string {
+	sorted := make([]string, 0, len(values))
+	for _, v := range values {
+		sorted = append(sorted, strings.ToLower(v))
+	}
+	sort.Strings(sorted)
+
+	h := sha256.Sum256([]byte(strings.Join(sorted, "|")))
+	return hex.EncodeToString(h[:8])
+}
+
+// UnitName - Converts a resource type, or any other value containing slashes, to a valid unit name
+func UnitName(value string) string {
+	return strings.ToLower(strings.ReplaceAll(value, "/", "_"))
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testOptions struct {
+	Defender bool `json:"defender"`
+}
+
+type testResult struct {
+	Name  string
+	Count int
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	options := testOptions{Defender: true}
+
+	s, err := NewStore(dir, false, options)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	want := []testResult{{Name: "a", Count: 1}, {Name: "b", Count: 2}}
+	s.Save("subscriptions/s1/azqr/microsoft.web_sites", want)
+
+	// a new scan never loads checkpoints
+	got := []testResult{}
+	if s.Load("subscriptions/s1/azqr/microsoft.web_sites", &got) {
+		t.Errorf("Store.Load() = true for a scan that is not resumed")
+	}
+
+	// the checkpoint is written with a rename, so no temporary file is left behind
+	path := filepath.Join(dir, "subscriptions", "s1", "azqr", "microsoft.web_sites.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Store.Save() didn't write %s: %v", path, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Store.Save() left the temporary file behind")
+	}
+
+	resumed, err := NewStore(dir, true, options)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if !resumed.Load("subscriptions/s1/azqr/microsoft.web_sites", &got) {
+		t.Fatalf("Store.Load() = false for a completed unit")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Store.Load() = %v, want %v", got, want)
+	}
+
+	// missing units are scanned
+	if resumed.Load("subscriptions/s2/defender", &got) {
+		t.Errorf("Store.Load() = true for a missing unit")
+	}
+
+	// partial checkpoints are scanned again
+	if err := os.WriteFile(filepath.Join(dir, "partial.json"), []byte(`[{"Name":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Load("partial", &got) {
+		t.Errorf("Store.Load() = true for a partial checkpoint")
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		saved   *testOptions
+		resume  bool
+		wantErr string
+	}{
+		{name: "new scan", saved: nil, resume: false},
+		{name: "new scan in a used directory", saved: &testOptions{Defender: true}, resume: false, wantErr: "already contains a scan"},
+		{name: "resume", saved: &testOptions{Defender: true}, resume: true},
+		{name: "resume with other options", saved: &testOptions{Defender: false}, resume: true, wantErr: "different options"},
+		{name: "resume without a scan", saved: nil, resume: true, wantErr: "does not contain a scan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.saved != nil {
+				if _, err := NewStore(dir, false, tt.saved); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := NewStore(dir, tt.resume, testOptions{Defender: true})
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("NewStore() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewStore() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		s, err := NewStore("", false, testOptions{})
+		if err != nil {
+			t.Fatalf("NewStore() error = %v", err)
+		}
+		s.Save("unit", testResult{})
+		if s.Load("unit", &testResult{}) {
+			t.Errorf("Store.Load() = true without a state directory")
+		}
+	})
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", "B", "c") != Key("c", "b", "A") {
+		t.Errorf("Key() depends on the order or case of the values")
+	}
+	if Key("a", "b") == Key("a", "c") {
+		t.Errorf("Key() returned the same key for different values")
+	}
+}
+
+func TestUnitName(t *testing.T) {
+	if got := UnitName("Microsoft.Web/sites"); got != "microsoft.web_sites" {
+		t.Errorf("UnitName() = %v, want microsoft.web_sites", got)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/renderers/excel"
+	"github.com/Azure/azqr/internal/renderers/json"
+	"github.com/Azure/azqr/internal/renderers/snapshot"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	DiffParams struct {
+		PreviousFile string
+		CurrentFile  string
+		OutputName   string
+		Mask         bool
+		Xlsx         bool
+		Json         bool
+		Debug        bool
+	}
+
+	Differ struct{}
+)
+
+// Diff compares two scan snapshots and reports new, resolved and unchanged findings
+func (d Differ) Diff(params *DiffParams) error {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if params.Debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		log.Debug().Msg("Debug logging enabled")
+	}
+
+	if params.PreviousFile == "" || params.CurrentFile == "" {
+		return errors.New("please specify the snapshot files to compare using --previous and --current options")
+	}
+
+	outputFile := generateOutputFileName(params.OutputName)
+
+	previous, err := snapshot.LoadSnapshot(params.PreviousFile, outputFile, params.Mask)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %s: %w", params.PreviousFile, err)
+	}
+
+	current, err := snapshot.LoadSnapshot(params.CurrentFile, outputFile, params.Mask)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %s: %w", params.CurrentFile, err)
+	}
+
+	current.Changes = renderers.Diff(previous, current)
+
+	d.printSummary(current)
+
+	// render excel report with the changes sheet
+	if params.Xlsx {
+		if err := excel.CreateExcelReport(current, excel.DefaultProfile()); err != nil {
+			return err
+		}
+	}
+
+	// render json changes report
+	if params.Json {
+		if err := json.CreateChangesReport(current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Differ) printSummary(data *renderers.ReportData) {
+	changes := data.Changes
+
+	fmt.Println("Findings:")
+	fmt.Printf("  %-10s %d\n", renderers.ChangeNew, changes.Count(renderers.ChangeNew))
+	fmt.Printf("  %-10s %d\n", renderers.ChangeResolved, changes.Count(renderers.ChangeResolved))
+	fmt.Printf("  %-10s %d\n", renderers.ChangeUnchanged, changes.Count(renderers.ChangeUnchanged))
+	fmt.Println("Inventory:")
+	fmt.Printf("  %-10s %d\n", renderers.ChangeAdded, changes.Count(renderers.ChangeAdded))
+	fmt.Printf("  %-10s %d\n", renderers.ChangeRemoved, changes.Count(renderers.ChangeRemoved))
+
+	for _, f := range changes.Findings {
+		if f.Status == renderers.ChangeUnchanged {
+			continue
+		}
+		fmt.Printf("%-10s | %s | %s | %s | %s\n", f.Status, f.Impact, f.RecommendationID, renderers.MaskSubscriptionIDInResourceID(f.ResourceID, data.Mask), f.Recommendation)
+	}
+
+	for _, r := range changes.Resources {
+		fmt.Printf("%-10s | %s | %s\n", r.Status, r.Type, renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask))
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/scanners"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	subscriptionIDRegex  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	resourceGroupIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}/resourceGroups/[^/]+$`)
+)
+
+type (
+	ValidateFiltersParams struct {
+		FilterFile   string
+		RulesDir     string
+		AprlRulesDir string
+		Debug        bool
+	}
+
+	FiltersValidator struct{}
+
+	// filtersProblems holds the errors and warnings found in a filters file
+	filtersProblems struct {
+		errors   []string
+		warnings []string
+	}
+)
+
+// Validate checks the filters file: unknown keys, unknown recommendation ids, malformed subscription, resource group
+// and resource ids are errors, and contradictory include and exclude entries are warnings
+func (v FiltersValidator) Validate(params *ValidateFiltersParams) error {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if params.Debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		log.Debug().Msg("Debug logging enabled")
+	}
+
+	if params.FilterFile == "" {
+		return errors.New("please specify the filters file")
+	}
+
+	filters, err := azqr.LoadFilters(params.FilterFile)
+	if err != nil {
+		return err
+	}
+
+	catalogue, err := v.catalogue(params)
+	if err != nil {
+		return err
+	}
+
+	p := validateFilters(filters.Azqr, catalogue)
+	for _, w := range p.warnings {
+		log.Warn().Msg(w)
+	}
+	for _, e := range p.errors {
+		log.Error().Msg(e)
+	}
+
+	if len(p.errors) > 0 {
+		return fmt.Errorf("filters file %s has %d errors", params.FilterFile, len(p.errors))
+	}
+
+	log.Info().Msgf("Filters file %s is valid (%d warnings)", params.FilterFile, len(p.warnings))
+	return nil
+}
+
+// catalogue returns the ids of the AZQR recommendations, including the custom rules, and the APRL recommendations,
+// including the custom APRL recommendations. Ids are lower case.
+func (v FiltersValidator) catalogue(params *ValidateFiltersParams) (map[string]bool, error) {
+	serviceScanners := scanners.GetScanners()
+	if err := azqr.LoadCustomRules(params.RulesDir, serviceScanners); err != nil {
+		return nil, err
+	}
+
+	aprl, err := AprlScanner{RulesDir: params.AprlRulesDir}.GetAprlRecommendations()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{}
+	for _, s := range serviceScanners {
+		for _, r := range azqr.GetRecommendations(s) {
+			ids[strings.ToLower(r.RecommendationID)] = true
+		}
+	}
+	for _, rt := range aprl {
+		for _, r := range rt {
+			ids[strings.ToLower(r.RecommendationID)] = true
+		}
+	}
+	return ids, nil
+}
+
+// validateFilters returns the problems of the filters, given the ids of the known recommendations
+func validateFilters(f *azqr.AzqrFilter, catalogue map[string]bool) filtersProblems {
+	p := filtersProblems{errors: []string{}, warnings: []string{}}
+
+	for _, section := range []struct {
+		name           string
+		subscriptions  []string
+		resourceGroups []string
+	}{
+		{"include", f.Include.Subscriptions, f.Include.ResourceGroups},
+		{"exclude", f.Exclude.Subscriptions, f.Exclude.ResourceGroups},
+	} {
+		for _, s := range section.subscriptions {
+			if !subscriptionIDRegex.MatchString(s) {
+				p.errorf("%s.subscriptions: %s is not a subscription id", section.name, s)
+			}
+		}
+		for _, rg := range section.resourceGroups {
+			if !resourceGroupIDRegex.MatchString(rg) {
+				p.errorf("%s.resourceGroups: %s is not a resource group id. Use /subscriptions/<subscription_id>/resourceGroups/<resource_group_name>", section.name, rg)
+			}
+		}
+	}
+
+	for _, s := range f.Exclude.Services {
+		if _, err := arm.ParseResourceID(s); err != nil || !strings.HasPrefix(strings.ToLower(s), "/subscriptions/") {
+			p.errorf("exclude.services: %s is not a resource id", s)
+		}
+	}
+
+	for _, id := range f.Exclude.Recommendations {
+		if !catalogue[strings.ToLower(id)] {
+			p.errorf("exclude.recommendations: unknown recommendation id %s", id)
+		}
+	}
+	for _, s := range f.Suppressions {
+		if !catalogue[strings.ToLower(s.RecommendationID)] {
+			p.errorf("suppressions: unknown recommendation id %s", s.RecommendationID)
+		}
+	}
+
+	// contradictory entries
+	for _, s := range overlap(f.Include.Subscriptions, f.Exclude.Subscriptions) {
+		p.warnf("subscription %s is both included and excluded. It is not scanned", s)
+	}
+	for _, rg := range overlap(f.Include.ResourceGroups, f.Exclude.ResourceGroups) {
+		p.warnf("resource group %s is both included and excluded. It is not scanned", rg)
+	}
+	for _, rg := range f.Include.ResourceGroups {
+		for _, s := range f.Exclude.Subscriptions {
+			if strings.HasPrefix(strings.ToLower(rg), strings.ToLower("/subscriptions/"+s+"/")) {
+				p.warnf("resource group %s is included but its subscription is excluded. It is not scanned", rg)
+			}
+		}
+	}
+	for _, s := range f.Exclude.Services {
+		for _, rg := range f.Exclude.ResourceGroups {
+			if strings.HasPrefix(strings.ToLower(s), strings.ToLower(rg+"/")) {
+				p.warnf("service %s is excluded twice, as its resource group is excluded", s)
+			}
+		}
+	}
+	for _, selector := range []struct {
+		name             string
+		include, exclude []string
+	}{
+		{"tag", f.Include.Tags, f.Exclude.Tags},
+		{"resource type", f.Include.ResourceTypes, f.Exclude.ResourceTypes},
+		{"location", f.Include.Locations, f.Exclude.Locations},
+		{"name", f.Include.Names, f.Exclude.Names},
+	} {
+		for _, v := range overlap(selector.include, selector.exclude) {
+			p.warnf("%s %s is both included and excluded. Matching resources are not scanned", selector.name, v)
+		}
+	}
+	for _, s := range f.Suppressions {
+		if f.IsRecommendationExcluded(s.RecommendationID) {
+			p.warnf("recommendation %s is suppressed and excluded. Its findings are never reported", s.RecommendationID)
+		}
+	}
+
+	return p
+}
+
+func (p *filtersProblems) errorf(format string, a ...interface{}) {
+	p.errors = append(p.errors, fmt.Sprintf(format, a...))
+}
+
+func (p *filtersProblems) warnf(format string, a ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, a...))
+}
+
+// overlap returns the values of a that are also in b, ignoring case
+func overlap(a, b []string) []string {
+	r := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				r = append(r, x)
+				break
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+)
+
+func writeFilters(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func Test_validateFilters(t *testing.T) {
+	file := writeFilters(t, `
+azqr:
+  include:
+    subscriptions: [00000000-0000-0000-0000-000000000001, not-a-guid]
+    resourceGroups:
+      - /subscriptions/00000000-0000-0000-0000-000000000002/resourceGroups/rg1
+      - rg2
+    locations: [westus]
+  exclude:
+    subscriptions: [00000000-0000-0000-0000-000000000001, 00000000-0000-0000-0000-000000000002]
+    resourceGroups: [/subscriptions/00000000-0000-0000-0000-000000000003/resourceGroups/rg3]
+    services:
+      - /subscriptions/00000000-0000-0000-0000-000000000003/resourceGroups/rg3/providers/Microsoft.Storage/storageAccounts/st
+      - st
+    recommendations: [ST-001, st-999]
+    locations: [WestUS]
+  suppressions:
+    - recommendationId: st-001
+      justification: Accepted
+      owner: me
+      expires: 2030-01-01
+    - recommendationId: aprl-999
+      justification: Accepted
+      owner: me
+      expires: 2030-01-01
+`)
+	filters, err := azqr.LoadFilters(file)
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+
+	p := validateFilters(filters.Azqr, map[string]bool{"st-001": true, "aprl-001": true})
+
+	wantErrors := []string{
+		"include.subscriptions: not-a-guid is not a subscription id",
+		"include.resourceGroups: rg2 is not a resource group id. Use /subscriptions/<subscription_id>/resourceGroups/<resource_group_name>",
+		"exclude.services: st is not a resource id",
+		"exclude.recommendations: unknown recommendation id st-999",
+		"suppressions: unknown recommendation id aprl-999",
+	}
+	if !reflect.DeepEqual(p.errors, wantErrors) {
+		t.Errorf("validateFilters() errors = %q, want %q", p.errors, wantErrors)
+	}
+
+	wantWarnings := []string{
+		"subscription 00000000-0000-0000-0000-000000000001 is both included and excluded. It is not scanned",
+		"resource group /subscriptions/00000000-0000-0000-0000-000000000002/resourceGroups/rg1 is included but its subscription is excluded. It is not scanned",
+		"service /subscriptions/00000000-0000-0000-0000-000000000003/resourceGroups/rg3/providers/Microsoft.Storage/storageAccounts/st is excluded twice, as its resource group is excluded",
+		"location westus is both included and excluded. Matching resources are not scanned",
+		"recommendation st-001 is suppressed and excluded. Its findings are never reported",
+	}
+	if !reflect.DeepEqual(p.warnings, wantWarnings) {
+		t.Errorf("validateFilters() warnings = %q, want %q", p.warnings, wantWarnings)
+	}
+}
+
+func TestFiltersValidator_Validate(t *testing.T) {
+	validator := FiltersValidator{}
+
+	valid := writeFilters(t, "azqr:\n  exclude:\n    recommendations: [st-001]\n")
+	if err := validator.Validate(&ValidateFiltersParams{FilterFile: valid}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := writeFilters(t, "azqr:\n  exclude:\n    recommendations: [st-999]\n")
+	if err := validator.Validate(&ValidateFiltersParams{FilterFile: invalid}); err == nil || !strings.Contains(err.Error(), "has 1 errors") {
+		t.Errorf("Validate() error = %v, want 1 error", err)
+	}
+
+	unknownKey := writeFilters(t, "azqr:\n  exclude:\n    recomendations: [st-001]\n")
+	if err := validator.Validate(&ValidateFiltersParams{FilterFile: unknownKey}); err == nil || !strings.Contains(err.Error(), "recomendations") {
+		t.Errorf("Validate() error = %v, want unknown key", err)
+	}
+}
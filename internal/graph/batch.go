@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxUnionLegs - Resource Graph allows a limited number of union legs in a single query
+	// https://learn.microsoft.com/en-us/azure/governance/resource-graph/concepts/query-language#supported-tabulartop-level-operators
+	maxUnionLegs = 3
+
+	batchIDColumn  = "azqrQueryId"
+	batchRowColumn = "azqrRow"
+)
+
+var (
+	// queries with these statements or operators can't be nested in a union leg, or would exceed the Resource Graph limits
+	unbatchableQuery = regexp.MustCompile(`(?i)\b(let|join|union|set|declare)\b`)
+	queryComment     = regexp.MustCompile(`(?m)^\s*//.*$`)
+)
+
+type (
+	// BatchQuery - A query that can be combined with other queries targeting the same resource type
+	BatchQuery struct {
+		ID           string
+		ResourceType string
+		Query        string
+	}
+
+	// BatchResult - The rows returned by each query of a batch, and the errors of the queries that failed
+	BatchResult struct {
+		Data   map[string][]interface{}
+		Errors map[string]error
+	}
+)
+
+// QueryBatch - Runs a set of queries with as few Resource Graph requests as possible.
+// Queries targeting the same resource type are combined with union and each row is tagged with the id of its query,
+// so the results are the same as running every query on its own. Queries that can't be combined, or combined queries
+// that fail, are run on their own.
+func (q *GraphQuery) QueryBatch(ctx context.Context, queries []BatchQuery, subscriptions []*string) *BatchResult {
+	result := &BatchResult{
+		Data:   map[string][]interface{}{},
+		Errors: map[string]error{},
+	}
+
+	for _, group := range groupQueries(queries) {
+		if len(group) == 1 {
+			q.querySingle(ctx, group[0], subscriptions, result)
+			continue
+		}
+
+		if err := q.queryUnion(ctx, group, subscriptions, result); err != nil {
+			log.Debug().Err(err).Msg("Failed to run combined Resource Graph query. Running queries one by one...")
+			for _, query := range group {
+				q.querySingle(ctx, query, subscriptions, result)
+			}
+		}
+	}
+
+	return result
+}
+
+func (q *GraphQuery) querySingle(ctx context.Context, query BatchQuery, subscriptions []*string, result *BatchResult) {
+	res, err := q.Query(ctx, query.Query, subscriptions)
+	if err != nil {
+		result.Errors[query.ID] = err
+		return
+	}
+	result.Data[query.ID] = res.Data
+}
+
+func (q *GraphQuery) queryUnion(ctx context.Context, group []BatchQuery, subscriptions []*string, result *BatchResult) error {
+	res, err := q.Query(ctx, unionQuery(group), subscriptions)
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]interface{}{}
+	for _, query := range group {
+		data[query.ID] = []interface{}{}
+	}
+
+	for _, r := range res.Data {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected row in combined query result: %v", r)
+		}
+		// the legs are tagged with their index, as numbers are decoded as float64
+		leg, ok := m[batchIDColumn].(float64)
+		if !ok || leg < 0 || int(leg) >= len(group) {
+			return fmt.Errorf("row without a query id in combined query result: %v", r)
+		}
+		id := group[int(leg)].ID
+		data[id] = append(data[id], m[batchRowColumn])
+	}
+
+	for id, rows := range data {
+		result.Data[id] = rows
+	}
+	return nil
+}
+
+// groupQueries groups the queries that can be combined by resource type, in groups of up to maxUnionLegs queries.
+// Every other query is returned in a group of its own.
+func groupQueries(queries []BatchQuery) [][]BatchQuery {
+	groups := [][]BatchQuery{}
+	byType := map[string][]BatchQuery{}
+	types := []string{}
+
+	for _, query := range queries {
+		if !canBatch(query.Query) {
+			groups = append(groups, []BatchQuery{query})
+			continue
+		}
+
+		t := strings.ToLower(query.ResourceType)
+		if _, ok := byType[t]; !ok {
+			types = append(types, t)
+		}
+		byType[t] = append(byType[t], query)
+	}
+
+	sort.Strings(types)
+	for _, t := range types {
+		typeQueries := byType[t]
+		for i := 0; i < len(typeQueries); i += maxUnionLegs {
+			j := i + maxUnionLegs
+			if j > len(typeQueries) {
+				j = len(typeQueries)
+			}
+			groups = append(groups, typeQueries[i:j])
+		}
+	}
+
+	return groups
+}
+
+func canBatch(query string) bool {
+	return !unbatchableQuery.MatchString(stripComments(query))
+}
+
+// unionQuery combines the queries in a single union. Each leg packs its rows in a single column,
+// so legs projecting columns of different types don't conflict, and tags them with the index of the leg.
+// The id column is kept, as Resource Graph only pages results that include it.
+func unionQuery(group []BatchQuery) string {
+	legs := make([]string, 0, len(group))
+	for i, query := range group {
+		legs = append(legs, fmt.Sprintf("(%s\n| extend %s = pack_all()\n| project id, %s = %d, %s)", stripComments(query.Query), batchRowColumn, batchIDColumn, i, batchRowColumn))
+	}
+	return "union " + strings.Join(legs, ",\n")
+}
+
+func stripComments(query string) string {
+	query = queryComment.ReplaceAllString(query, "")
+	return strings.TrimRight(strings.TrimSpace(query), ";")
+}
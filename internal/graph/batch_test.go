@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/to"
+)
+
+// fakeBatchTransport answers single queries with the rows of the query and combined queries with the rows
+// of each leg, packed and tagged the same way Resource Graph does. With a page size, results are paged with a
+// skip token, which Resource Graph only returns when the results include the id column.
+type fakeBatchTransport struct {
+	rows      map[string][]map[string]interface{}
+	failUnion bool
+	pageSize  int
+	queries   []string
+}
+
+var unionLeg = regexp.MustCompile(`\(([^\n]*)\n\| extend ` + batchRowColumn + ` = pack_all\(\)\n\| project (id, )?` + batchIDColumn + ` = (\d+), ` + batchRowColumn + `\)`)
+
+func (f *fakeBatchTransport) Do(req *http.Request) (*http.Response, error) {
+	body := struct {
+		Query   string `json:"query"`
+		Options struct {
+			SkipToken string `json:"$skipToken"`
+		} `json:"options"`
+	}{}
+	content, _ := io.ReadAll(req.Body)
+	_ = json.Unmarshal(content, &body)
+	if body.Options.SkipToken == "" {
+		f.queries = append(f.queries, body.Query)
+	}
+
+	data := []interface{}{}
+	status := http.StatusOK
+	hasID := true
+	if strings.HasPrefix(body.Query, "union ") {
+		if f.failUnion {
+			status = http.StatusBadRequest
+		}
+		for _, m := range unionLeg.FindAllStringSubmatch(body.Query, -1) {
+			hasID = hasID && m[2] != ""
+			leg, _ := strconv.Atoi(m[3])
+			for _, r := range f.rows[m[1]] {
+				data = append(data, map[string]interface{}{"id": r["id"], batchIDColumn: leg, batchRowColumn: r})
+			}
+		}
+	} else {
+		for _, r := range f.rows[body.Query] {
+			data = append(data, r)
+		}
+	}
+
+	response := map[string]interface{}{"totalRecords": len(data), "resultTruncated": "false"}
+	if f.pageSize > 0 {
+		skip, _ := strconv.Atoi(body.Options.SkipToken)
+		end := skip + f.pageSize
+		if end < len(data) && hasID {
+			response["$skipToken"] = strconv.Itoa(end)
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		data = data[skip:end]
+	}
+	response["count"] = len(data)
+	response["data"] = data
+
+	js, _ := json.Marshal(response)
+	if status != http.StatusOK {
+		js = []byte(`{"error":{"code":"BadRequest","message":"Query is invalid"}}`)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(js))),
+		Request:    req,
+	}, nil
+}
+
+func TestGraphQuery_QueryBatch(t *testing.T) {
+	queries := []BatchQuery{
+		{ID: "q1", ResourceType: "Microsoft.Web/sites", Query: "q1"},
+		{ID: "q2", ResourceType: "Microsoft.Web/sites", Query: "q2"},
+		{ID: "q3", ResourceType: "microsoft.web/sites", Query: "q3"},
+		{ID: "q4", ResourceType: "Microsoft.Web/sites", Query: "q4"},
+		{ID: "q5", ResourceType: "Microsoft.Storage/storageAccounts", Query: "q5 | join (resources) on id"},
+	}
+	rows := map[string][]map[string]interface{}{
+		"q1":                          {{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/a", "param1": "x"}},
+		"q2":                          {},
+		"q3":                          {{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/a", "param1": float64(1)}, {"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/b"}},
+		"q4":                          {{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/c"}},
+		"q5 | join (resources) on id": {{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/d"}},
+	}
+
+	want := map[string][]interface{}{}
+	for _, q := range queries {
+		want[q.ID] = []interface{}{}
+		for _, r := range rows[q.Query] {
+			want[q.ID] = append(want[q.ID], r)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		failUnion   bool
+		pageSize    int
+		wantQueries int
+	}{
+		{name: "combines queries of the same resource type", failUnion: false, wantQueries: 3},
+		{name: "falls back to single queries", failUnion: true, wantQueries: 6},
+		{name: "pages combined queries", failUnion: false, pageSize: 1, wantQueries: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			transport := &fakeBatchTransport{
+				rows:      rows,
+				failUnion: tt.failUnion,
+				pageSize:  tt.pageSize,
+			}
+			q, err := newGraphQuery(fakeCredential{}, newTestLimiter(clock), transport)
+			if err != nil {
+				t.Fatalf("newGraphQuery() error = %v", err)
+			}
+
+			got := q.QueryBatch(context.Background(), queries, []*string{to.Ptr("s")})
+			if len(got.Errors) > 0 {
+				t.Fatalf("GraphQuery.QueryBatch() errors = %v", got.Errors)
+			}
+			// compare through json, as the fake rows are decoded from the response
+			gotJs, _ := json.Marshal(got.Data)
+			wantJs, _ := json.Marshal(want)
+			if !reflect.DeepEqual(string(gotJs), string(wantJs)) {
+				t.Errorf("GraphQuery.QueryBatch() = %s, want %s", gotJs, wantJs)
+			}
+			if len(transport.queries) != tt.wantQueries {
+				t.Errorf("GraphQuery.QueryBatch() sent %d queries, want %d", len(transport.queries), tt.wantQueries)
+			}
+		})
+	}
+}
+
+func Test_unionQuery(t *testing.T) {
+	got := unionQuery([]BatchQuery{
+		{ID: "it's-1", Query: "resources\n// comment\n| where type =~ 'microsoft.web/sites'"},
+		{ID: "it's-2", Query: "resources;"},
+	})
+	want := "union (resources\n\n| where type =~ 'microsoft.web/sites'\n| extend azqrRow = pack_all()\n| project id, azqrQueryId = 0, azqrRow),\n" +
+		"(resources\n| extend azqrRow = pack_all()\n| project id, azqrQueryId = 1, azqrRow)"
+	if got != want {
+		t.Errorf("unionQuery() = %q, want %q", got, want)
+	}
+}
+
+func Test_canBatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "resources\n| where type =~ 'microsoft.web/sites'", want: true},
+		{query: "// let is mentioned in a comment\nresources", want: true},
+		{query: "let x = 1;\nresources", want: false},
+		{query: "resources\n| join kind=leftouter (resources) on id", want: false},
+		{query: "resources\n| union resourcecontainers", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := canBatch(tt.query); got != tt.want {
+				t.Errorf("canBatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,17 +5,21 @@ package graph
 
 import (
 	"context"
-	"time"
+	"errors"
+	"fmt"
 
 	"github.com/Azure/azqr/internal/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	arg "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	"github.com/rs/zerolog/log"
 )
 
 type (
 	GraphQuery struct {
-		client *arg.Client
+		client          *arg.Client
+		managementGroup string
 	}
 
 	GraphResult struct {
@@ -23,84 +27,83 @@ type (
 	}
 )
 
-func NewGraphQuery(cred azcore.TokenCredential) *GraphQuery {
-	client, err := arg.NewClient(cred, nil)
+func NewGraphQuery(cred azcore.TokenCredential) (*GraphQuery, error) {
+	return newGraphQuery(cred, userQuota, nil)
+}
+
+// newGraphQuery creates a client that spends the given quota. Throttled requests are retried by the pipeline
+// once the quota resets.
+func newGraphQuery(cred azcore.TokenCredential, quota *quotaLimiter, transport policy.Transporter) (*GraphQuery, error) {
+	options := &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Retry: policy.RetryOptions{
+				MaxRetries: 3,
+			},
+			PerRetryPolicies: []policy.Policy{quota},
+			Transport:        transport,
+		},
+	}
+
+	client, err := arg.NewClient(cred, options)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Resource Graph client")
-		return nil
+		return nil, fmt.Errorf("failed to create resource graph client: %w", err)
 	}
 	return &GraphQuery{
 		client: client,
-	}
+	}, nil
 }
 
-func (q *GraphQuery) Query(ctx context.Context, query string, subscriptions []*string) *GraphResult {
+// ScopeToManagementGroup - Runs the queries at the scope of the management group instead of its subscriptions
+func (q *GraphQuery) ScopeToManagementGroup(managementGroupID string) {
+	q.managementGroup = managementGroupID
+}
+
+func (q *GraphQuery) Query(ctx context.Context, query string, subscriptions []*string) (*GraphResult, error) {
 	result := GraphResult{
 		Data: make([]interface{}, 0),
 	}
 
-	// Run the query in batches of 300 subscriptions
-	batchSize := 300
-	for i := 0; i < len(subscriptions); i += batchSize {
-		j := i + batchSize
-		if j > len(subscriptions) {
-			j = len(subscriptions)
+	requests := []arg.QueryRequest{}
+	if q.managementGroup != "" {
+		// a single request covers every subscription of the management group
+		requests = append(requests, arg.QueryRequest{ManagementGroups: []*string{&q.managementGroup}})
+	} else {
+		// Run the query in batches of 300 subscriptions
+		batchSize := 300
+		for i := 0; i < len(subscriptions); i += batchSize {
+			j := i + batchSize
+			if j > len(subscriptions) {
+				j = len(subscriptions)
+			}
+			requests = append(requests, arg.QueryRequest{Subscriptions: subscriptions[i:j]})
 		}
+	}
 
+	for _, request := range requests {
 		format := arg.ResultFormatObjectArray
-		request := arg.QueryRequest{
-			Subscriptions: subscriptions[i:j],
-			Query:         &query,
-			Options: &arg.QueryRequestOptions{
-				ResultFormat: &format,
-				Top:          to.Ptr(int32(1000)),
-			},
+		request.Query = &query
+		request.Options = &arg.QueryRequestOptions{
+			ResultFormat: &format,
+			Top:          to.Ptr(int32(1000)),
 		}
 
 		if q.client == nil {
-			log.Fatal().Msg("Resource Graph client not initialized")
+			return nil, errors.New("resource graph client not initialized")
 		}
 
 		var skipToken *string = nil
 		for ok := true; ok; ok = skipToken != nil {
 			request.Options.SkipToken = skipToken
 			// Run the query and get the results
-			results, err := q.retry(ctx, 3, 10*time.Second, request)
+			results, err := q.client.Resources(ctx, request, nil)
 			if err == nil {
 				result.Data = append(result.Data, results.Data.([]interface{})...)
 				skipToken = results.SkipToken
 			} else {
-				log.Fatal().Err(err).Msgf("Failed to run Resource Graph query: %s", query)
-				return nil
+				log.Debug().Msgf("Failed to run Resource Graph query: %s", query)
+				return nil, fmt.Errorf("failed to run resource graph query: %w", err)
 			}
 		}
 	}
-	return &result
-}
-
-func (q *GraphQuery) retry(ctx context.Context, attempts int, sleep time.Duration, request arg.QueryRequest) (arg.ClientResourcesResponse, error) {
-	var err error
-	for i := 0; ; i++ {
-		res, err := q.client.Resources(ctx, request, nil)
-		if err == nil {
-			return res, nil
-		}
-
-		// if shouldSkipError(err) {
-		// 	return []azqr.AzureServiceResult{}, nil
-		// }
-
-		errAsString := err.Error()
-
-		if i >= (attempts - 1) {
-			log.Info().Msgf("Retry limit reached. Error: %s", errAsString)
-			break
-		}
-
-		log.Debug().Msgf("Retrying after error: %s", errAsString)
-
-		time.Sleep(sleep)
-		sleep *= 2
-	}
-	return arg.ClientResourcesResponse{}, err
+	return &result, nil
 }
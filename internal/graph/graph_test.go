@@ -0,0 +1,90 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/to"
+)
+
+// fakeScopeTransport records the scope of each request and returns one row per request
+type fakeScopeTransport struct {
+	subscriptions    []int
+	managementGroups [][]string
+}
+
+func (f *fakeScopeTransport) Do(req *http.Request) (*http.Response, error) {
+	body := struct {
+		Subscriptions    []string `json:"subscriptions"`
+		ManagementGroups []string `json:"managementGroups"`
+	}{}
+	content, _ := io.ReadAll(req.Body)
+	_ = json.Unmarshal(content, &body)
+	f.subscriptions = append(f.subscriptions, len(body.Subscriptions))
+	f.managementGroups = append(f.managementGroups, body.ManagementGroups)
+
+	js, _ := json.Marshal(map[string]interface{}{
+		"totalRecords":    1,
+		"count":           1,
+		"resultTruncated": "false",
+		"data":            []interface{}{map[string]interface{}{"id": fmt.Sprintf("row%d", len(f.subscriptions))}},
+	})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(js))),
+		Request:    req,
+	}, nil
+}
+
+func TestGraphQuery_Query_Scope(t *testing.T) {
+	subscriptions := make([]*string, 301)
+	for i := range subscriptions {
+		subscriptions[i] = to.Ptr(fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+	}
+
+	tests := []struct {
+		name                 string
+		managementGroup      string
+		wantSubscriptions    []int
+		wantManagementGroups [][]string
+	}{
+		{name: "batches of subscriptions", wantSubscriptions: []int{300, 1}, wantManagementGroups: [][]string{nil, nil}},
+		{name: "management group scope", managementGroup: "corp", wantSubscriptions: []int{0}, wantManagementGroups: [][]string{{"corp"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			transport := &fakeScopeTransport{}
+			q, err := newGraphQuery(fakeCredential{}, newTestLimiter(clock), transport)
+			if err != nil {
+				t.Fatalf("newGraphQuery() error = %v", err)
+			}
+			q.ScopeToManagementGroup(tt.managementGroup)
+
+			result, err := q.Query(context.Background(), "resources", subscriptions)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if len(result.Data) != len(tt.wantSubscriptions) {
+				t.Errorf("Query() rows = %d, want %d", len(result.Data), len(tt.wantSubscriptions))
+			}
+			if !reflect.DeepEqual(transport.subscriptions, tt.wantSubscriptions) {
+				t.Errorf("Query() subscriptions per request = %v, want %v", transport.subscriptions, tt.wantSubscriptions)
+			}
+			if !reflect.DeepEqual(transport.managementGroups, tt.wantManagementGroups) {
+				t.Errorf("Query() management groups per request = %v, want %v", transport.managementGroups, tt.wantManagementGroups)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package graph
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azqr/internal/throttling"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// quotaCapacity - Resource Graph default quota: 15 queries...
+	quotaCapacity = 15
+	// quotaWindow - ...every 5 seconds, per user.
+	// https://learn.microsoft.com/en-us/azure/governance/resource-graph/concepts/guidance-for-throttled-requests
+	quotaWindow = 5 * time.Second
+
+	headerQuotaRemaining   = "x-ms-user-quota-remaining"
+	headerQuotaResetsAfter = "x-ms-user-quota-resets-after"
+)
+
+// quotaLimiter - azcore pipeline policy that spends a token of the user quota on every Resource Graph request.
+// The bucket starts full, so small scans are not delayed, and it is kept in sync with the quota reported
+// by Resource Graph in the response headers, so large scans wait exactly until the quota resets.
+type quotaLimiter struct {
+	mu       sync.Mutex
+	tokens   int
+	capacity int
+	window   time.Duration
+	resetAt  time.Time
+
+	now  func() time.Time
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+// the quota is per user, so all the graph clients of the process share the same limiter
+var userQuota = newQuotaLimiter(quotaCapacity, quotaWindow)
+
+func newQuotaLimiter(capacity int, window time.Duration) *quotaLimiter {
+	return &quotaLimiter{
+		tokens:   capacity,
+		capacity: capacity,
+		window:   window,
+		now:      time.Now,
+		wait:     wait,
+	}
+}
+
+// Do - Implements policy.Policy
+func (l *quotaLimiter) Do(req *policy.Request) (*http.Response, error) {
+	if err := l.take(req.Raw().Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := req.Next()
+	if resp != nil {
+		l.update(resp)
+	}
+	return resp, err
+}
+
+// take blocks until a token is available and spends it
+func (l *quotaLimiter) take(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		if !now.Before(l.resetAt) {
+			l.tokens = l.capacity
+			l.resetAt = now.Add(l.window)
+		}
+		if l.tokens > 0 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		d := l.resetAt.Sub(now)
+		l.mu.Unlock()
+
+		log.Debug().Msgf("Resource Graph quota exhausted. Waiting %s for it to reset", d)
+		if err := l.wait(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// update syncs the bucket with the quota reported by Resource Graph
+func (l *quotaLimiter) update(resp *http.Response) {
+	remaining, hasRemaining := parseQuotaRemaining(resp.Header.Get(headerQuotaRemaining))
+	resetsAfter, hasResetsAfter := parseQuotaResetsAfter(resp.Header.Get(headerQuotaResetsAfter))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		remaining, hasRemaining = 0, true
+		if d := throttling.RetryAfter(resp.Header); d > resetsAfter {
+			resetsAfter, hasResetsAfter = d, true
+		}
+	}
+
+	if !hasRemaining && !hasResetsAfter {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// responses of concurrent requests can arrive out of order, so the quota is never raised
+	if hasRemaining && remaining < l.tokens {
+		l.tokens = remaining
+	}
+	if hasResetsAfter {
+		l.resetAt = l.now().Add(resetsAfter)
+	}
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func parseQuotaRemaining(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	remaining, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || remaining < 0 {
+		return 0, false
+	}
+	return remaining, true
+}
+
+// parseQuotaResetsAfter parses the hh:mm:ss duration of the x-ms-user-quota-resets-after header
+func parseQuotaResetsAfter(value string) (time.Duration, bool) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	var d time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(p, 64)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		d += time.Duration(n * float64(units[i]))
+	}
+	return d, true
+}
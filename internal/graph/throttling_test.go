@@ -0,0 +1,231 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// fakeClock replaces the time of the limiter, so waits are recorded instead of slept
+type fakeClock struct {
+	mu    sync.Mutex
+	t     time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) wait(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	c.waits = append(c.waits, d)
+	return nil
+}
+
+// fakeGraphTransport simulates the Resource Graph user quota and reports it in the response headers
+type fakeGraphTransport struct {
+	clock         *fakeClock
+	quota         int
+	window        time.Duration
+	remaining     int
+	resetAt       time.Time
+	requests      int
+	throttled     int
+	throttleFirst int
+}
+
+func (f *fakeGraphTransport) Do(req *http.Request) (*http.Response, error) {
+	now := f.clock.now()
+	if !now.Before(f.resetAt) {
+		f.remaining = f.quota
+		f.resetAt = now.Add(f.window)
+	}
+	f.requests++
+
+	status := http.StatusOK
+	body := `{"totalRecords":1,"count":1,"resultTruncated":"false","data":[{"id":"/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Web/sites/app"}]}`
+	if f.remaining == 0 || f.throttleFirst > 0 {
+		f.remaining = 0
+		f.throttleFirst--
+		f.throttled++
+		status = http.StatusTooManyRequests
+		body = `{"error":{"code":"RateLimiting","message":"Please provide below info when asking for support"}}`
+	} else {
+		f.remaining--
+	}
+
+	resetsAfter := int(math.Ceil(f.resetAt.Sub(now).Seconds()))
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(headerQuotaRemaining, strconv.Itoa(f.remaining))
+	header.Set(headerQuotaResetsAfter, fmt.Sprintf("00:00:%02d", resetsAfter))
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+type fakeCredential struct{}
+
+func (c fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newTestLimiter(clock *fakeClock) *quotaLimiter {
+	l := newQuotaLimiter(quotaCapacity, quotaWindow)
+	l.now = clock.now
+	l.wait = clock.wait
+	return l
+}
+
+func send(t *testing.T, pl runtime.Pipeline) *http.Response {
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://management.azure.com/providers/Microsoft.ResourceGraph/resources")
+	if err != nil {
+		t.Fatalf("runtime.NewRequest() error = %v", err)
+	}
+	resp, err := pl.Do(req)
+	if err != nil {
+		t.Fatalf("Pipeline.Do() error = %v", err)
+	}
+	return resp
+}
+
+func Test_quotaLimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		quota         int
+		throttleFirst int
+		requests      int
+		wantWaits     []time.Duration
+		wantThrottled int
+	}{
+		{
+			name:          "small scan runs at full speed",
+			quota:         15,
+			requests:      15,
+			wantWaits:     nil,
+			wantThrottled: 0,
+		},
+		{
+			name:          "waits for the quota to reset",
+			quota:         15,
+			requests:      31,
+			wantWaits:     []time.Duration{5 * time.Second, 5 * time.Second},
+			wantThrottled: 0,
+		},
+		{
+			name:          "follows a quota lower than the default",
+			quota:         5,
+			requests:      11,
+			wantWaits:     []time.Duration{5 * time.Second, 5 * time.Second},
+			wantThrottled: 0,
+		},
+		{
+			name:          "throttled request is retried when the quota resets",
+			quota:         15,
+			throttleFirst: 1,
+			requests:      1,
+			wantWaits:     []time.Duration{5 * time.Second},
+			wantThrottled: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			transport := &fakeGraphTransport{clock: clock, quota: tt.quota, window: quotaWindow, throttleFirst: tt.throttleFirst}
+			pl := runtime.NewPipeline("azqr", "test", runtime.PipelineOptions{PerRetry: []policy.Policy{newTestLimiter(clock)}}, &policy.ClientOptions{
+				Transport: transport,
+				Retry: policy.RetryOptions{
+					MaxRetries: 3,
+					RetryDelay: time.Millisecond,
+				},
+			})
+
+			for i := 0; i < tt.requests; i++ {
+				resp := send(t, pl)
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("request %d: status = %d, want %d", i, resp.StatusCode, http.StatusOK)
+				}
+			}
+
+			if transport.throttled != tt.wantThrottled {
+				t.Errorf("throttled requests = %d, want %d", transport.throttled, tt.wantThrottled)
+			}
+			if fmt.Sprint(clock.waits) != fmt.Sprint(tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", clock.waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestGraphQuery_Query(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	transport := &fakeGraphTransport{clock: clock, quota: 10, window: quotaWindow}
+
+	q, err := newGraphQuery(fakeCredential{}, newTestLimiter(clock), transport)
+	if err != nil {
+		t.Fatalf("newGraphQuery() error = %v", err)
+	}
+
+	for i := 0; i < 25; i++ {
+		result, err := q.Query(context.Background(), "resources", []*string{to.Ptr("00000000-0000-0000-0000-000000000000")})
+		if err != nil {
+			t.Fatalf("GraphQuery.Query() error = %v", err)
+		}
+		if len(result.Data) != 1 {
+			t.Errorf("GraphQuery.Query() returned %d rows, want 1", len(result.Data))
+		}
+	}
+
+	if transport.throttled != 0 {
+		t.Errorf("throttled requests = %d, want 0", transport.throttled)
+	}
+	if len(clock.waits) != 2 {
+		t.Errorf("waits = %v, want 2 waits", clock.waits)
+	}
+}
+
+func Test_parseQuotaResetsAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "00:00:05", want: 5 * time.Second, wantOk: true},
+		{value: "00:01:02", want: 62 * time.Second, wantOk: true},
+		{value: "00:00:00.5", want: 500 * time.Millisecond, wantOk: true},
+		{value: "", want: 0, wantOk: false},
+		{value: "5", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseQuotaResetsAfter(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseQuotaResetsAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
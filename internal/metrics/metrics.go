@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ProtocolOtlp - Publishes the metrics to an OTLP/HTTP endpoint
+	ProtocolOtlp = "otlp"
+	// ProtocolPushgateway - Publishes the metrics to a Prometheus pushgateway
+	ProtocolPushgateway = "pushgateway"
+)
+
+type (
+	// Exporter - Publishes the posture metrics of a scan
+	Exporter interface {
+		Export(ctx context.Context, gauges []Gauge) error
+	}
+
+	// Gauge - Metric with a value for each combination of labels
+	Gauge struct {
+		Name   string
+		Help   string
+		Labels []string
+		Points []Point
+	}
+
+	// Point - Value of a gauge for the given label values, in the order of the gauge labels
+	Point struct {
+		Values []string
+		Value  float64
+	}
+)
+
+// NewExporter - Returns the exporter of the given protocol, publishing to the given endpoint
+func NewExporter(protocol, endpoint string) (Exporter, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("metrics endpoint is required")
+	}
+
+	switch strings.ToLower(protocol) {
+	case ProtocolOtlp:
+		return &OtlpExporter{URL: endpoint}, nil
+	case ProtocolPushgateway:
+		return &PushgatewayExporter{URL: endpoint, Job: "azqr"}, nil
+	}
+	return nil, fmt.Errorf("invalid metrics protocol %s. Valid protocols are %s and %s", protocol, ProtocolOtlp, ProtocolPushgateway)
+}
+
+// Export - Publishes the metrics of the report data with the given exporter
+func Export(ctx context.Context, exporter Exporter, data *renderers.ReportData) error {
+	log.Info().Msg("Exporting metrics")
+	if err := exporter.Export(ctx, NewGauges(data)); err != nil {
+		return fmt.Errorf("error exporting metrics: %w", err)
+	}
+	return nil
+}
+
+// NewGauges - Computes the gauges of the report data: the non-compliant findings, the resources of each type and the costs.
+// Subscription ids are masked unless --mask=false is used.
+func NewGauges(data *renderers.ReportData) []Gauge {
+	findings := counter{}
+	add := func(subscriptionID, subscriptionName, resourceType string, category azqr.RecommendationCategory, impact azqr.RecommendationImpact) {
+		findings.add(1, renderers.MaskSubscriptionID(subscriptionID, data.Mask), subscriptionName, strings.ToLower(resourceType), string(category), string(impact))
+	}
+	for _, f := range data.Findings() {
+		add(f.SubscriptionID, f.SubscriptionName, f.ResourceType, f.Category, f.Impact)
+	}
+
+	resources := counter{}
+	for _, r := range data.ResourceTypeCount {
+		resources.add(r.Count, r.Subscription, strings.ToLower(r.ResourceType))
+	}
+
+	costs := counter{}
+	for _, c := range data.CostData.Items {
+		value, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping cost of %s", c.ServiceName)
+			continue
+		}
+		costs.add(value, renderers.MaskSubscriptionID(c.SubscriptionID, data.Mask), c.SubscriptionName, c.Currency)
+	}
+
+	return []Gauge{
+		{
+			Name:   "azqr_findings",
+			Help:   "Number of non-compliant findings",
+			Labels: []string{"subscription_id", "subscription_name", "resource_type", "category", "impact"},
+			Points: findings.points(),
+		},
+		{
+			Name:   "azqr_resources",
+			Help:   "Number of resources",
+			Labels: []string{"subscription_name", "resource_type"},
+			Points: resources.points(),
+		},
+		{
+			Name:   "azqr_cost",
+			Help:   "Total cost of the scanned period",
+			Labels: []string{"subscription_id", "subscription_name", "currency"},
+			Points: costs.points(),
+		},
+	}
+}
+
+// counter sums values by label values
+type counter map[string]*Point
+
+func (c counter) add(value float64, labels ...string) {
+	key := strings.Join(labels, "\x00")
+	if _, ok := c[key]; !ok {
+		c[key] = &Point{Values: labels}
+	}
+	c[key].Value += value
+}
+
+// points returns the points sorted by label values
+func (c counter) points() []Point {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	points := make([]Point, 0, len(c))
+	for _, k := range keys {
+		points = append(points, *c[k])
+	}
+	return points
+}
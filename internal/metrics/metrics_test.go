@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+	collector "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData() *renderers.ReportData {
+	data := renderers.NewReportData("", true)
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: "Production",
+			Type:             "Microsoft.Web/sites",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh, NotCompliant: true},
+				"app-002": {RecommendationID: "app-002", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh},
+				"app-sla": {RecommendationID: "app-sla", RecommendationType: azqr.TypeSLA, NotCompliant: true},
+			},
+		},
+	}
+	data.AprlData = []azqr.AprlResult{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", ResourceType: "microsoft.web/sites", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh},
+	}
+	data.ResourceTypeCount = []azqr.ResourceTypeCount{
+		{Subscription: "Production", ResourceType: "Microsoft.Web/sites", Count: 3},
+	}
+	data.CostData.Items = []*scanners.CostResultItem{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", ServiceName: "Azure App Service", Value: "10.5", Currency: "EUR"},
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", ServiceName: "Storage", Value: "2", Currency: "EUR"},
+	}
+	return &data
+}
+
+func TestNewGauges(t *testing.T) {
+	gauges := NewGauges(newTestData())
+	if len(gauges) != 3 {
+		t.Fatalf("NewGauges() = %d gauges, want 3", len(gauges))
+	}
+
+	tests := []struct {
+		gauge  Gauge
+		values string
+		value  float64
+	}{
+		// the APRL and AZQR findings of the same resource type are added, SLAs are not findings
+		{gauges[0], "xxxxxxxx-xxxx-xxxx-xxxx-xxxxx0000001,Production,microsoft.web/sites,Security,High", 2},
+		{gauges[1], "Production,microsoft.web/sites", 3},
+		{gauges[2], "xxxxxxxx-xxxx-xxxx-xxxx-xxxxx0000001,Production,EUR", 12.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gauge.Name, func(t *testing.T) {
+			if len(tt.gauge.Points) != 1 {
+				t.Fatalf("NewGauges() %s = %d points, want 1", tt.gauge.Name, len(tt.gauge.Points))
+			}
+			p := tt.gauge.Points[0]
+			if got := strings.Join(p.Values, ","); got != tt.values || p.Value != tt.value {
+				t.Errorf("NewGauges() %s = %s %v, want %s %v", tt.gauge.Name, got, p.Value, tt.values, tt.value)
+			}
+		})
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		protocol string
+		endpoint string
+		wantErr  bool
+	}{
+		{ProtocolOtlp, "http://localhost:4318/v1/metrics", false},
+		{"Pushgateway", "http://localhost:9091", false},
+		{"statsd", "http://localhost:9091", true},
+		{ProtocolOtlp, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			if _, err := NewExporter(tt.protocol, tt.endpoint); (err != nil) != tt.wantErr {
+				t.Errorf("NewExporter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// request is a request received by the stand-in server
+type request struct {
+	method string
+	path   string
+	body   []byte
+}
+
+func newServer(t *testing.T) (*httptest.Server, chan request) {
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		requests <- request{r.Method, r.URL.Path, body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestPushgatewayExporter(t *testing.T) {
+	server, requests := newServer(t)
+
+	exporter, _ := NewExporter(ProtocolPushgateway, server.URL)
+	if err := Export(context.Background(), exporter, newTestData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	r := <-requests
+	if r.method != http.MethodPut || r.path != "/metrics/job/azqr" {
+		t.Errorf("Export() = %s %s, want PUT /metrics/job/azqr", r.method, r.path)
+	}
+	for _, want := range []string{"azqr_findings", "azqr_resources", "azqr_cost", "microsoft.web/sites", "Security"} {
+		if !strings.Contains(string(r.body), want) {
+			t.Errorf("Export() body doesn't contain %s", want)
+		}
+	}
+}
+
+func TestOtlpExporter(t *testing.T) {
+	server, requests := newServer(t)
+
+	exporter, _ := NewExporter(ProtocolOtlp, server.URL+"/v1/metrics")
+	if err := Export(context.Background(), exporter, newTestData()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	r := <-requests
+	if r.method != http.MethodPost || r.path != "/v1/metrics" {
+		t.Errorf("Export() = %s %s, want POST /v1/metrics", r.method, r.path)
+	}
+
+	export := &collector.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(r.body, export); err != nil {
+		t.Fatalf("Export() sent an invalid request: %v", err)
+	}
+
+	values := map[string]float64{}
+	for _, rm := range export.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				for _, p := range m.GetGauge().DataPoints {
+					values[m.Name] += p.GetAsDouble()
+				}
+			}
+		}
+	}
+	if values["azqr_findings"] != 2 || values["azqr_resources"] != 3 || values["azqr_cost"] != 12.5 {
+		t.Errorf("Export() values = %v", values)
+	}
+}
+
+func TestExport_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exporter, _ := NewExporter(ProtocolPushgateway, server.URL)
+	if err := Export(context.Background(), exporter, newTestData()); err == nil {
+		t.Errorf("Export() error = nil, want the error of the server")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OtlpExporter - Publishes the metrics to an OTLP/HTTP endpoint, such as http://localhost:4318/v1/metrics
+type OtlpExporter struct {
+	URL string
+}
+
+// Export - Records the gauges and sends them in a single OTLP request
+func (e *OtlpExporter) Export(ctx context.Context, gauges []Gauge) error {
+	exporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(e.URL))
+	if err != nil {
+		return err
+	}
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", "azqr"))),
+	)
+	meter := provider.Meter("github.com/Azure/azqr")
+
+	for _, g := range gauges {
+		gauge, err := meter.Float64Gauge(g.Name, metric.WithDescription(g.Help))
+		if err != nil {
+			return err
+		}
+
+		for _, p := range g.Points {
+			attributes := make([]attribute.KeyValue, 0, len(g.Labels))
+			for i, l := range g.Labels {
+				attributes = append(attributes, attribute.String(l, p.Values[i]))
+			}
+			gauge.Record(ctx, p.Value, metric.WithAttributes(attributes...))
+		}
+	}
+
+	rm := metricdata.ResourceMetrics{}
+	if err := reader.Collect(ctx, &rm); err != nil {
+		return err
+	}
+
+	err = exporter.Export(ctx, &rm)
+	return errors.Join(err, exporter.Shutdown(ctx), provider.Shutdown(ctx))
+}
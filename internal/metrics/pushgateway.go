@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushgatewayExporter - Publishes the metrics to a Prometheus pushgateway, replacing the metrics of the previous scan
+type PushgatewayExporter struct {
+	URL string
+	Job string
+}
+
+// Export - Pushes the gauges to the pushgateway
+func (e *PushgatewayExporter) Export(ctx context.Context, gauges []Gauge) error {
+	registry := prometheus.NewRegistry()
+	for _, g := range gauges {
+		vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: g.Name, Help: g.Help}, g.Labels)
+		if err := registry.Register(vec); err != nil {
+			return err
+		}
+
+		for _, p := range g.Points {
+			vec.WithLabelValues(p.Values...).Set(p.Value)
+		}
+	}
+
+	return push.New(e.URL, e.Job).Gatherer(registry).PushContext(ctx)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package notify
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+)
+
+type (
+	// Payload - Summary of a scan available to the webhook template
+	Payload struct {
+		Generated time.Time
+		RunID     string
+		// Findings is the number of APRL findings and non-compliant AZQR recommendations
+		Findings int
+		ByImpact []Count
+		// NewFindings is the number of findings that are not in the baseline scan. Without a baseline every finding is new.
+		NewFindings  int
+		NewHighCount int
+		// NewHigh is the first N new High impact findings
+		NewHigh []Finding
+	}
+
+	// Count - Number of findings of an impact
+	Count struct {
+		Name  string
+		Count int
+	}
+
+	// Finding - New finding. Subscription ids are masked unless --mask=false is used.
+	Finding struct {
+		Source           string
+		Category         string
+		Impact           string
+		ResourceType     string
+		Recommendation   string
+		RecommendationID string
+		SubscriptionID   string
+		SubscriptionName string
+		ResourceGroup    string
+		Name             string
+		ResourceID       string
+	}
+)
+
+// NewPayload - Summarizes the findings of the scan and the findings that are new since the baseline scan, if any
+func NewPayload(data, baseline *renderers.ReportData, top int) Payload {
+	if baseline == nil {
+		empty := renderers.NewReportData("", data.Mask)
+		baseline = &empty
+	}
+
+	payload := Payload{
+		Generated: time.Now().UTC(),
+		RunID:     data.RunID,
+		ByImpact:  []Count{},
+		NewHigh:   []Finding{},
+	}
+
+	impacts := map[string]int{}
+	newHigh := []Finding{}
+	for _, f := range renderers.Diff(baseline, data).Findings {
+		if f.Status == renderers.ChangeResolved {
+			continue
+		}
+		payload.Findings++
+		impacts[f.Impact]++
+
+		if f.Status != renderers.ChangeNew {
+			continue
+		}
+		payload.NewFindings++
+		if f.Impact == string(azqr.ImpactHigh) {
+			newHigh = append(newHigh, Finding{
+				Source:           f.Source,
+				Category:         f.Category,
+				Impact:           f.Impact,
+				ResourceType:     f.ResourceType,
+				Recommendation:   f.Recommendation,
+				RecommendationID: f.RecommendationID,
+				SubscriptionID:   renderers.MaskSubscriptionID(f.SubscriptionID, data.Mask),
+				SubscriptionName: f.SubscriptionName,
+				ResourceGroup:    f.ResourceGroup,
+				Name:             f.Name,
+				ResourceID:       renderers.MaskSubscriptionIDInResourceID(f.ResourceID, data.Mask),
+			})
+		}
+	}
+
+	for _, i := range []azqr.RecommendationImpact{azqr.ImpactHigh, azqr.ImpactMedium, azqr.ImpactLow} {
+		payload.ByImpact = append(payload.ByImpact, Count{Name: string(i), Count: impacts[string(i)]})
+	}
+
+	sort.SliceStable(newHigh, func(i, j int) bool {
+		a, b := newHigh[i], newHigh[j]
+		if a.SubscriptionName != b.SubscriptionName {
+			return a.SubscriptionName < b.SubscriptionName
+		}
+		return a.ResourceType < b.ResourceType
+	})
+	payload.NewHighCount = len(newHigh)
+	if len(newHigh) > top {
+		newHigh = newHigh[:top]
+	}
+	payload.NewHigh = append(payload.NewHigh, newHigh...)
+
+	return payload
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Azure/azqr/internal/embeded"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultRetries - Default number of times a failed notification is retried
+	DefaultRetries = 3
+	// DefaultTop - Default number of new High impact findings included in the payload
+	DefaultTop = 10
+)
+
+// Webhook - Posts the payload rendered with a template to a URL
+type Webhook struct {
+	URL      string
+	Headers  http.Header
+	Template *template.Template
+	// Retries is the number of times a request is retried after a network error, a 429 or a 5xx response.
+	// The delay between retries starts at RetryDelay and doubles after each attempt.
+	Retries    int
+	RetryDelay time.Duration
+	// DryRun writes the payload to Out instead of posting it
+	DryRun bool
+	Out    io.Writer
+	Client *http.Client
+}
+
+// NewWebhook - Returns a webhook with the given template file (or the default template if empty) and "Name: value" headers
+func NewWebhook(url, templateFile string, headers []string, retries int, dryRun bool) (*Webhook, error) {
+	if url == "" && !dryRun {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+
+	t, err := LoadTemplate(templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := ParseHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		URL:        url,
+		Headers:    h,
+		Template:   t,
+		Retries:    retries,
+		RetryDelay: time.Second,
+		DryRun:     dryRun,
+		Out:        os.Stdout,
+		Client:     http.DefaultClient,
+	}, nil
+}
+
+// LoadTemplate - Parses the given template file or, if empty, the default template.
+// Besides the text/template functions, templates can use json, to encode a value as JSON, and include, to render a named template to a string.
+func LoadTemplate(templateFile string) (*template.Template, error) {
+	content := embeded.GetTemplates("webhook.json")
+	if templateFile != "" {
+		var err error
+		content, err = os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading webhook template: %w", err)
+		}
+	}
+
+	t := template.New("webhook")
+	t.Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			js, err := json.Marshal(v)
+			return string(js), err
+		},
+		"include": func(name string, data interface{}) (string, error) {
+			var buf bytes.Buffer
+			err := t.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+	})
+
+	if _, err := t.Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("error parsing webhook template: %w", err)
+	}
+	return t, nil
+}
+
+// ParseHeaders - Parses "Name: value" headers
+func ParseHeaders(headers []string) (http.Header, error) {
+	h := http.Header{}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid webhook header %s. Use the \"Name: value\" format", header)
+		}
+		h.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return h, nil
+}
+
+// Render - Renders the payload with the template and checks that it is valid JSON
+func (w *Webhook) Render(payload Payload) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("error rendering webhook payload: %w", err)
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook payload is not valid json: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// Notify - Posts the rendered payload to the URL, retrying on transient failures, or prints it in dry-run mode
+func (w *Webhook) Notify(ctx context.Context, payload Payload) error {
+	body, err := w.Render(payload)
+	if err != nil {
+		return err
+	}
+
+	if w.DryRun {
+		log.Info().Msg("Webhook dry-run: the payload is not posted")
+		_, err := fmt.Fprintln(w.Out, string(body))
+		return err
+	}
+
+	log.Info().Msgf("Posting webhook notification to %s", w.URL)
+	delay := w.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.Retries {
+			return fmt.Errorf("error posting webhook notification: %w", err)
+		}
+
+		log.Warn().Err(err).Msgf("Webhook notification failed, retrying in %s", delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends the payload once. It returns whether a failed request can be retried.
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range w.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
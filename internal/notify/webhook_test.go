@@ -0,0 +1,179 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData(names ...string) *renderers.ReportData {
+	data := renderers.NewReportData("", true)
+	for _, name := range names {
+		data.AzqrData = append(data.AzqrData, azqr.AzqrServiceResult{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: "Production",
+			ResourceGroup:    "rg",
+			Type:             "Microsoft.Web/sites",
+			ServiceName:      name,
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Recommendation: "App Service should use TLS 1.2", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh, NotCompliant: true},
+				"app-002": {RecommendationID: "app-002", Recommendation: "App Service should use zones", Category: azqr.CategoryHighAvailability, Impact: azqr.ImpactLow, NotCompliant: true},
+			},
+		})
+	}
+	return &data
+}
+
+func TestNewPayload(t *testing.T) {
+	// app1 was in the baseline, app2 and app3 are new
+	payload := NewPayload(newTestData("app1", "app2", "app3"), newTestData("app1"), 1)
+
+	if payload.Findings != 6 || payload.NewFindings != 4 || payload.NewHighCount != 2 {
+		t.Errorf("NewPayload() findings = %d, new = %d, new high = %d, want 6, 4, 2", payload.Findings, payload.NewFindings, payload.NewHighCount)
+	}
+	if len(payload.ByImpact) != 3 || payload.ByImpact[0].Count != 3 || payload.ByImpact[1].Count != 0 || payload.ByImpact[2].Count != 3 {
+		t.Errorf("NewPayload() by impact = %v, want 3 High, 0 Medium and 3 Low", payload.ByImpact)
+	}
+	if len(payload.NewHigh) != 1 || payload.NewHigh[0].Name != "app2" {
+		t.Fatalf("NewPayload() new high = %v, want the top 1: app2", payload.NewHigh)
+	}
+	if strings.Contains(payload.NewHigh[0].ResourceID, subscriptionID) {
+		t.Errorf("NewPayload() resource id = %s, want masked", payload.NewHigh[0].ResourceID)
+	}
+
+	// without a baseline every finding is new
+	if payload := NewPayload(newTestData("app1"), nil, DefaultTop); payload.NewFindings != 2 || payload.NewHighCount != 1 {
+		t.Errorf("NewPayload() without baseline new = %d, new high = %d, want 2, 1", payload.NewFindings, payload.NewHighCount)
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(server.URL, "", []string{"Authorization: Bearer token", "X-Team: cloud"}, DefaultRetries, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(context.Background(), NewPayload(newTestData("app1"), nil, DefaultTop)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if header.Get("Authorization") != "Bearer token" || header.Get("X-Team") != "cloud" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("Notify() headers = %v", header)
+	}
+
+	payload := map[string]string{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Notify() payload is not valid json: %v", err)
+	}
+	for _, want := range []string{"2 findings (1 High, 0 Medium, 1 Low), 2 of them new", "App Service should use TLS 1.2 (app-001): app1 in Production"} {
+		if !strings.Contains(payload["text"], want) {
+			t.Errorf("Notify() text = %q, doesn't contain %q", payload["text"], want)
+		}
+	}
+}
+
+func TestWebhook_Retry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		wantErr  bool
+		attempts int32
+	}{
+		{"retries server errors", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, 2, false, 3},
+		{"gives up after the retries", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, 1, true, 2},
+		{"doesn't retry client errors", []int{http.StatusBadRequest, http.StatusOK}, 2, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := atomic.AddInt32(&attempts, 1) - 1
+				w.WriteHeader(tt.statuses[i])
+			}))
+			defer server.Close()
+
+			w, _ := NewWebhook(server.URL, "", nil, tt.retries, false)
+			w.RetryDelay = 0
+			err := w.Notify(context.Background(), NewPayload(newTestData("app1"), nil, DefaultTop))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("Notify() attempts = %d, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestWebhook_DryRun(t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewWebhook("", "", nil, DefaultRetries, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Out = &out
+
+	if err := w.Notify(context.Background(), NewPayload(newTestData("app1"), nil, DefaultTop)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if !json.Valid(out.Bytes()) {
+		t.Errorf("Notify() dry-run printed %s, want the json payload", out.String())
+	}
+}
+
+func TestWebhook_Template(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	_ = os.WriteFile(valid, []byte(`{"high": {{(index .ByImpact 0).Count}}, "run": {{json .RunID}}}`), 0644)
+	_ = os.WriteFile(invalid, []byte(`{"text": {{.RunID}}}`), 0644)
+
+	w, err := NewWebhook("", valid, nil, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := newTestData("app1")
+	body, err := w.Render(NewPayload(data, nil, DefaultTop))
+	if err != nil || string(body) != `{"high": 1, "run": "`+data.RunID+`"}` {
+		t.Errorf("Render() = %s, %v", body, err)
+	}
+
+	w, _ = NewWebhook("", invalid, nil, 0, true)
+	if _, err := w.Render(NewPayload(data, nil, DefaultTop)); err == nil {
+		t.Errorf("Render() error = nil, want invalid json")
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	h, err := ParseHeaders([]string{"X-Token: a:b"})
+	if err != nil || h.Get("X-Token") != "a:b" {
+		t.Errorf("ParseHeaders() = %v, %v", h, err)
+	}
+	if _, err := ParseHeaders([]string{"X-Token"}); err == nil {
+		t.Errorf("ParseHeaders() error = nil, want invalid header")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/renderers/csv"
+	"github.com/Azure/azqr/internal/renderers/datalake"
+	"github.com/Azure/azqr/internal/renderers/excel"
+	"github.com/Azure/azqr/internal/renderers/html"
+	"github.com/Azure/azqr/internal/renderers/json"
+	"github.com/Azure/azqr/internal/renderers/junit"
+	"github.com/Azure/azqr/internal/renderers/markdown"
+	"github.com/Azure/azqr/internal/renderers/sarif"
+	"github.com/Azure/azqr/internal/renderers/snapshot"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	RenderParams struct {
+		InputFile  string
+		OutputName string
+		Mask       bool
+		Csv        bool
+		Json       bool
+		Sarif      bool
+		JUnit      bool
+		Html       bool
+		Markdown   bool
+		// MarkdownTemplate is the template file of the markdown report. The default template is used if empty.
+		MarkdownTemplate string
+		Ndjson           bool
+		Parquet          bool
+		// ExcelProfile is the workbook profile of the excel report. The default layout is used if empty.
+		ExcelProfile string
+		Debug        bool
+	}
+
+	Renderer struct{}
+
+	// reportFormats holds the optional reports rendered along with the excel report
+	reportFormats struct {
+		Csv   bool
+		Json  bool
+		Sarif bool
+		JUnit bool
+		Html  bool
+		// Markdown renders the executive summary with MarkdownTemplate, or the default template if empty
+		Markdown         bool
+		MarkdownTemplate string
+		// Ndjson and Parquet export the tables of the report for data lake ingestion
+		Ndjson  bool
+		Parquet bool
+		// ExcelProfile is the workbook profile of the excel report, or the default layout if empty
+		ExcelProfile string
+	}
+)
+
+// Render generates reports from a snapshot file, without connecting to Azure
+func (r Renderer) Render(params *RenderParams) error {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if params.Debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		log.Debug().Msg("Debug logging enabled")
+	}
+
+	if params.InputFile == "" {
+		return errors.New("please specify the snapshot file using --input option")
+	}
+
+	outputFile := generateOutputFileName(params.OutputName)
+
+	reportData, err := snapshot.LoadSnapshot(params.InputFile, outputFile, params.Mask)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot %s: %w", params.InputFile, err)
+	}
+
+	formats := reportFormats{
+		Csv:              params.Csv,
+		Json:             params.Json,
+		Sarif:            params.Sarif,
+		JUnit:            params.JUnit,
+		Html:             params.Html,
+		Markdown:         params.Markdown,
+		MarkdownTemplate: params.MarkdownTemplate,
+		Ndjson:           params.Ndjson,
+		Parquet:          params.Parquet,
+		ExcelProfile:     params.ExcelProfile,
+	}
+	if err := renderReports(reportData, formats); err != nil {
+		return err
+	}
+
+	log.Info().Msg("Render completed.")
+	return nil
+}
+
+// renderReports renders the excel report and the other requested reports
+func renderReports(data *renderers.ReportData, formats reportFormats) error {
+	// render excel report
+	profile, err := excel.LoadProfile(formats.ExcelProfile)
+	if err != nil {
+		return err
+	}
+	if err := excel.CreateExcelReport(data, profile); err != nil {
+		return err
+	}
+
+	// render json report
+	if formats.Json {
+		if err := json.CreateJsonReport(data); err != nil {
+			return err
+		}
+	}
+
+	// render csv reports
+	if formats.Csv {
+		if err := csv.CreateCsvReport(data); err != nil {
+			return err
+		}
+	}
+
+	// render sarif report
+	if formats.Sarif {
+		if err := sarif.CreateSarifReport(data); err != nil {
+			return err
+		}
+	}
+
+	// render junit report
+	if formats.JUnit {
+		if err := junit.CreateJUnitReport(data); err != nil {
+			return err
+		}
+	}
+
+	// render html report
+	if formats.Html {
+		if err := html.CreateHtmlReport(data); err != nil {
+			return err
+		}
+	}
+
+	// render markdown summary
+	if formats.Markdown {
+		if err := markdown.CreateMarkdownReport(data, formats.MarkdownTemplate); err != nil {
+			return err
+		}
+	}
+
+	// render data lake exports
+	if formats.Ndjson {
+		if err := datalake.CreateNdjsonReport(data); err != nil {
+			return err
+		}
+	}
+
+	if formats.Parquet {
+		if err := datalake.CreateParquetReport(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
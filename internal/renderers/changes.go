@@ -0,0 +1,263 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package renderers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+)
+
+const (
+	ChangeNew       = "New"
+	ChangeResolved  = "Resolved"
+	ChangeUnchanged = "Unchanged"
+	ChangeAdded     = "Added"
+	ChangeRemoved   = "Removed"
+)
+
+type (
+	// ChangesData - Differences between two scans
+	ChangesData struct {
+		Findings  []FindingChange  `json:"findings"`
+		Resources []ResourceChange `json:"resources"`
+	}
+
+	// FindingChange - A finding classified as new, resolved or unchanged
+	FindingChange struct {
+		Status           string `json:"status"`
+		Source           string `json:"source"`
+		Category         string `json:"category"`
+		Impact           string `json:"impact"`
+		ResourceType     string `json:"resourceType"`
+		Recommendation   string `json:"recommendation"`
+		RecommendationID string `json:"recommendationId"`
+		SubscriptionID   string `json:"subscriptionId"`
+		SubscriptionName string `json:"subscriptionName"`
+		ResourceGroup    string `json:"resourceGroup"`
+		Name             string `json:"name"`
+		ResourceID       string `json:"resourceId"`
+	}
+
+	// ResourceChange - A resource added to or removed from the inventory
+	ResourceChange struct {
+		Status         string `json:"status"`
+		SubscriptionID string `json:"subscriptionId"`
+		ResourceGroup  string `json:"resourceGroup"`
+		Location       string `json:"location"`
+		Type           string `json:"type"`
+		Name           string `json:"name"`
+		ResourceID     string `json:"resourceId"`
+	}
+)
+
+// Diff - Compares the findings and inventory of two scans
+func Diff(previous, current *ReportData) *ChangesData {
+	before := previous.findings()
+	after := current.findings()
+
+	changes := &ChangesData{
+		Findings:  []FindingChange{},
+		Resources: []ResourceChange{},
+	}
+
+	// a finding suppressed in either scan is not new or resolved, only its suppression was added or expired
+	suppressed := suppressedKeys(previous, current)
+
+	for k, f := range after {
+		if suppressed[k] {
+			continue
+		}
+		if _, ok := before[k]; ok {
+			f.Status = ChangeUnchanged
+		} else {
+			f.Status = ChangeNew
+		}
+		changes.Findings = append(changes.Findings, f)
+	}
+
+	for k, f := range before {
+		if _, ok := after[k]; !ok && !suppressed[k] {
+			f.Status = ChangeResolved
+			changes.Findings = append(changes.Findings, f)
+		}
+	}
+
+	beforeResources := map[string]ResourceChange{}
+	for _, r := range previous.Resources {
+		beforeResources[strings.ToLower(r.ID)] = newResourceChange(r)
+	}
+
+	afterResources := map[string]ResourceChange{}
+	for _, r := range current.Resources {
+		afterResources[strings.ToLower(r.ID)] = newResourceChange(r)
+	}
+
+	for k, r := range afterResources {
+		if _, ok := beforeResources[k]; !ok {
+			r.Status = ChangeAdded
+			changes.Resources = append(changes.Resources, r)
+		}
+	}
+
+	for k, r := range beforeResources {
+		if _, ok := afterResources[k]; !ok {
+			r.Status = ChangeRemoved
+			changes.Resources = append(changes.Resources, r)
+		}
+	}
+
+	sort.Slice(changes.Findings, func(i, j int) bool {
+		a, b := changes.Findings[i], changes.Findings[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+		return a.RecommendationID < b.RecommendationID
+	})
+
+	sort.Slice(changes.Resources, func(i, j int) bool {
+		a, b := changes.Resources[i], changes.Resources[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.ResourceID < b.ResourceID
+	})
+
+	return changes
+}
+
+// Count - Returns the number of findings or resources with the given status
+func (c *ChangesData) Count(status string) int {
+	count := 0
+	for _, f := range c.Findings {
+		if f.Status == status {
+			count++
+		}
+	}
+	for _, r := range c.Resources {
+		if r.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (rd *ReportData) ChangesTable() [][]string {
+	headers := []string{"Change", "Source", "Category", "Impact", "Resource Type", "Recommendation", "Recommendation Id", "Subscription Id", "Subscription Name", "Resource Group", "Name", "Id"}
+
+	rows := [][]string{}
+	if rd.Changes != nil {
+		for _, f := range rd.Changes.Findings {
+			row := []string{
+				f.Status,
+				f.Source,
+				f.Category,
+				f.Impact,
+				f.ResourceType,
+				f.Recommendation,
+				f.RecommendationID,
+				MaskSubscriptionID(f.SubscriptionID, rd.Mask),
+				f.SubscriptionName,
+				f.ResourceGroup,
+				f.Name,
+				MaskSubscriptionIDInResourceID(f.ResourceID, rd.Mask),
+			}
+			rows = append(rows, row)
+		}
+
+		for _, r := range rd.Changes.Resources {
+			row := []string{
+				r.Status,
+				"Inventory",
+				"",
+				"",
+				r.Type,
+				"",
+				"",
+				MaskSubscriptionID(r.SubscriptionID, rd.Mask),
+				"",
+				r.ResourceGroup,
+				r.Name,
+				MaskSubscriptionIDInResourceID(r.ResourceID, rd.Mask),
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	rows = append([][]string{headers}, rows...)
+	return rows
+}
+
+// suppressedKeys returns the keys of the suppressed findings of the scans
+func suppressedKeys(scans ...*ReportData) map[string]bool {
+	keys := map[string]bool{}
+	for _, rd := range scans {
+		for _, r := range rd.Suppressed {
+			keys[findingKey(r.ResourceID, r.RecommendationID)] = true
+		}
+	}
+	return keys
+}
+
+// findings returns the APRL results and the non compliant AZQR results keyed by resource and recommendation id
+func (rd *ReportData) findings() map[string]FindingChange {
+	findings := map[string]FindingChange{}
+
+	for _, r := range rd.AprlData {
+		findings[findingKey(r.ResourceID, r.RecommendationID)] = FindingChange{
+			Source:           r.Source,
+			Category:         string(r.Category),
+			Impact:           string(r.Impact),
+			ResourceType:     r.ResourceType,
+			Recommendation:   r.Recommendation,
+			RecommendationID: r.RecommendationID,
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			ResourceGroup:    r.ResourceGroup,
+			Name:             r.Name,
+			ResourceID:       r.ResourceID,
+		}
+	}
+
+	for _, d := range rd.AzqrData {
+		for _, r := range d.Recommendations {
+			if r.NotCompliant {
+				findings[findingKey(d.ResourceID(), r.RecommendationID)] = FindingChange{
+					Source:           "AZQR",
+					Category:         string(r.Category),
+					Impact:           string(r.Impact),
+					ResourceType:     d.Type,
+					Recommendation:   r.Recommendation,
+					RecommendationID: r.RecommendationID,
+					SubscriptionID:   d.SubscriptionID,
+					SubscriptionName: d.SubscriptionName,
+					ResourceGroup:    d.ResourceGroup,
+					Name:             d.ServiceName,
+					ResourceID:       d.ResourceID(),
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+func findingKey(resourceID, recommendationID string) string {
+	return strings.ToLower(resourceID) + "|" + strings.ToLower(recommendationID)
+}
+
+func newResourceChange(r *azqr.Resource) ResourceChange {
+	return ResourceChange{
+		SubscriptionID: r.SubscriptionID,
+		ResourceGroup:  r.ResourceGroup,
+		Location:       r.Location,
+		Type:           r.Type,
+		Name:           r.Name,
+		ResourceID:     r.ID,
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package renderers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+)
+
+func TestDiff(t *testing.T) {
+	const (
+		site    = "/subscriptions/s1/resourceGroups/rg/providers/Microsoft.Web/sites/app"
+		storage = "/subscriptions/s1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st"
+		vault   = "/subscriptions/s1/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"
+	)
+
+	previous := &ReportData{
+		AprlData: []azqr.AprlResult{
+			{RecommendationID: "aprl-1", ResourceID: site, Source: "APRL"},
+			{RecommendationID: "aprl-2", ResourceID: site, Source: "APRL"},
+		},
+		AzqrData: []azqr.AzqrServiceResult{
+			{
+				SubscriptionID: "s1", ResourceGroup: "rg", Type: "Microsoft.Storage/storageAccounts", ServiceName: "st",
+				Recommendations: map[string]azqr.AzqrResult{
+					"st-001": {RecommendationID: "st-001", NotCompliant: true},
+					"st-002": {RecommendationID: "st-002", NotCompliant: false},
+				},
+			},
+		},
+		Resources: []*azqr.Resource{{ID: site}, {ID: storage}},
+	}
+
+	current := &ReportData{
+		AprlData: []azqr.AprlResult{
+			// keys are compared case insensitive
+			{RecommendationID: "APRL-1", ResourceID: "/SUBSCRIPTIONS/S1/resourceGroups/RG/providers/Microsoft.Web/sites/APP", Source: "APRL"},
+			{RecommendationID: "aprl-3", ResourceID: vault, Source: "APRL"},
+		},
+		AzqrData: []azqr.AzqrServiceResult{
+			{
+				SubscriptionID: "s1", ResourceGroup: "rg", Type: "Microsoft.Storage/storageAccounts", ServiceName: "st",
+				Recommendations: map[string]azqr.AzqrResult{
+					"st-001": {RecommendationID: "st-001", NotCompliant: true},
+					// compliant results are not findings, so they are never new
+					"st-003": {RecommendationID: "st-003", NotCompliant: false},
+				},
+			},
+		},
+		Resources: []*azqr.Resource{{ID: "/SUBSCRIPTIONS/S1/resourceGroups/rg/providers/Microsoft.Web/sites/app"}, {ID: vault}},
+	}
+
+	got := Diff(previous, current)
+
+	type finding struct{ status, id, resource string }
+	gotFindings := []finding{}
+	for _, f := range got.Findings {
+		gotFindings = append(gotFindings, finding{f.Status, f.RecommendationID, f.ResourceID})
+	}
+	wantFindings := []finding{
+		{ChangeNew, "aprl-3", vault},
+		{ChangeResolved, "aprl-2", site},
+		{ChangeUnchanged, "APRL-1", "/SUBSCRIPTIONS/S1/resourceGroups/RG/providers/Microsoft.Web/sites/APP"},
+		{ChangeUnchanged, "st-001", "/subscriptions/s1/resourcegroups/rg/providers/microsoft.storage/storageaccounts/st"},
+	}
+	if !reflect.DeepEqual(gotFindings, wantFindings) {
+		t.Errorf("Diff() findings = %v, want %v", gotFindings, wantFindings)
+	}
+
+	gotResources := map[string]string{}
+	for _, r := range got.Resources {
+		gotResources[r.ResourceID] = r.Status
+	}
+	wantResources := map[string]string{vault: ChangeAdded, storage: ChangeRemoved}
+	if !reflect.DeepEqual(gotResources, wantResources) {
+		t.Errorf("Diff() resources = %v, want %v", gotResources, wantResources)
+	}
+
+	if got.Count(ChangeNew) != 1 || got.Count(ChangeResolved) != 1 || got.Count(ChangeUnchanged) != 2 || got.Count(ChangeAdded) != 1 || got.Count(ChangeRemoved) != 1 {
+		t.Errorf("ChangesData.Count() = %d new, %d resolved, %d unchanged, %d added, %d removed",
+			got.Count(ChangeNew), got.Count(ChangeResolved), got.Count(ChangeUnchanged), got.Count(ChangeAdded), got.Count(ChangeRemoved))
+	}
+}
+
+func TestDiff_Empty(t *testing.T) {
+	got := Diff(&ReportData{}, &ReportData{})
+	if len(got.Findings) != 0 || len(got.Resources) != 0 {
+		t.Errorf("Diff() = %v, want no changes", got)
+	}
+}
+
+func TestDiff_Suppressed(t *testing.T) {
+	const site = "/subscriptions/s1/resourceGroups/rg/providers/Microsoft.Web/sites/app"
+	finding := azqr.AprlResult{RecommendationID: "aprl-1", ResourceID: site, Source: "APRL"}
+	suppressed := azqr.SuppressedResult{RecommendationID: "aprl-1", ResourceID: site, Source: "APRL", Justification: "accepted"}
+
+	open := &ReportData{AprlData: []azqr.AprlResult{finding}}
+	accepted := &ReportData{Suppressed: []azqr.SuppressedResult{suppressed}}
+
+	// suppression on, then off: the finding is neither resolved nor new
+	for _, scans := range [][2]*ReportData{{open, accepted}, {accepted, open}} {
+		if got := Diff(scans[0], scans[1]); len(got.Findings) != 0 {
+			t.Errorf("Diff() findings = %v, want no changes", got.Findings)
+		}
+	}
+
+	// the other findings are still compared
+	other := azqr.AprlResult{RecommendationID: "aprl-2", ResourceID: site, Source: "APRL"}
+	got := Diff(open, &ReportData{AprlData: []azqr.AprlResult{other}, Suppressed: []azqr.SuppressedResult{suppressed}})
+	if len(got.Findings) != 1 || got.Findings[0].Status != ChangeNew || got.Findings[0].RecommendationID != "aprl-2" {
+		t.Errorf("Diff() findings = %v, want aprl-2 new", got.Findings)
+	}
+}
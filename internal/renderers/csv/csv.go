@@ -13,42 +13,50 @@ import (
 	"github.com/Azure/azqr/internal/renderers"
 )
 
-func CreateCsvReport(data *renderers.ReportData) {
-	records := data.RecommendationsTable()
-	writeData(records, data.OutputFileName, "recommendations")
-
-	records = data.ImpactedTable()
-	writeData(records, data.OutputFileName, "impacted")
-
-	records = data.ResourceTypesTable()
-	writeData(records, data.OutputFileName, "resourceType")
-
-	records = data.ResourcesTable()
-	writeData(records, data.OutputFileName, "inventory")
+// table is a report table and the extension of the csv file it is written to
+type table struct {
+	records   [][]string
+	extension string
+}
 
-	records = data.DefenderTable()
-	writeData(records, data.OutputFileName, "defender")
+func CreateCsvReport(data *renderers.ReportData) error {
+	tables := []table{
+		{data.RecommendationsTable(), "recommendations"},
+		{data.ImpactedTable(), "impacted"},
+		{data.ResourceTypesTable(), "resourceType"},
+		{data.ResourcesTable(), "inventory"},
+		{data.DefenderTable(), "defender"},
+		{data.AdvisorTable(), "advisor"},
+		{data.CostTable(), "costs"},
+	}
 
-	records = data.AdvisorTable()
-	writeData(records, data.OutputFileName, "advisor")
+	if len(data.Errors) > 0 {
+		tables = append(tables, table{data.ErrorsTable(), "errors"})
+	}
 
-	records = data.CostTable()
-	writeData(records, data.OutputFileName, "costs")
+	for _, t := range tables {
+		if err := writeData(t.records, data.OutputFileName, t.extension); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func writeData(data [][]string, fileName, extension string) {
+func writeData(data [][]string, fileName, extension string) error {
 	filename := fmt.Sprintf("%s.%s.csv", fileName, extension)
 	log.Info().Msgf("Generating Report: %s", filename)
 
 	f, err := os.Create(filename)
 	if err != nil {
-		log.Fatal().Err(err).Msg("error creating csv:")
+		return fmt.Errorf("error creating csv: %w", err)
 	}
+	defer f.Close()
 
 	w := csv.NewWriter(f)
 	err = w.WriteAll(data) // calls Flush internally
 
 	if err != nil {
-		log.Fatal().Err(w.Error()).Msg("error writing csv:")
+		return fmt.Errorf("error writing csv: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package datalake
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/parquet-go/parquet-go"
+	"github.com/rs/zerolog/log"
+)
+
+// table is the rows of a report table and the name of the file they are written to
+type table struct {
+	rows func(write func(row interface{}) error) error
+	name string
+	// schema is an empty row, used to build the parquet schema of the table
+	schema interface{}
+}
+
+// CreateNdjsonReport - Writes each table of the report to <OutputFileName>.<table>.ndjson, one row per line
+func CreateNdjsonReport(data *renderers.ReportData) error {
+	for _, t := range tables(NewTables(data)) {
+		if err := writeNdjson(t, data.OutputFileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateParquetReport - Writes each table of the report to <OutputFileName>.<table>.parquet
+func CreateParquetReport(data *renderers.ReportData) error {
+	for _, t := range tables(NewTables(data)) {
+		if err := writeParquet(t, data.OutputFileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tables(t Tables) []table {
+	return []table{
+		{rowsOf(t.Recommendations), "recommendations", new(Recommendation)},
+		{rowsOf(t.Impacted), "impacted", new(Impacted)},
+		{rowsOf(t.ResourceTypes), "resource_types", new(ResourceType)},
+		{rowsOf(t.Inventory), "inventory", new(Resource)},
+		{rowsOf(t.Defender), "defender", new(Defender)},
+		{rowsOf(t.Advisor), "advisor", new(Advisor)},
+		{rowsOf(t.Costs), "costs", new(Cost)},
+		{rowsOf(t.Errors), "errors", new(Error)},
+	}
+}
+
+// rowsOf returns a function that writes each row of a table
+func rowsOf[T any](rows []T) func(write func(row interface{}) error) error {
+	return func(write func(row interface{}) error) error {
+		for _, r := range rows {
+			if err := write(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func writeNdjson(t table, fileName string) error {
+	filename := fmt.Sprintf("%s.%s.ndjson", fileName, t.name)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating ndjson: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	// the encoder writes a newline after each row
+	enc := json.NewEncoder(w)
+	if err := t.rows(enc.Encode); err != nil {
+		return fmt.Errorf("error writing ndjson: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error writing ndjson: %w", err)
+	}
+	return nil
+}
+
+func writeParquet(t table, fileName string) error {
+	filename := fmt.Sprintf("%s.%s.parquet", fileName, t.name)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating parquet: %w", err)
+	}
+	defer f.Close()
+
+	// every field is a required column named after its parquet tag. The fields of embedded structs are flattened.
+	pw := parquet.NewWriter(f, parquet.SchemaOf(t.schema))
+	if err := t.rows(pw.Write); err != nil {
+		return fmt.Errorf("error writing parquet: %w", err)
+	}
+
+	if err := pw.Close(); err != nil {
+		return fmt.Errorf("error writing parquet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package datalake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+	"github.com/parquet-go/parquet-go"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData(t *testing.T) *renderers.ReportData {
+	data := renderers.NewReportData(filepath.Join(t.TempDir(), "azqr_report"), true)
+	data.RunID = "run-1"
+	data.ScannedAt = time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	data.Recomendations = map[string]map[string]azqr.AprlRecommendation{
+		"microsoft.web/sites": {
+			"app-001": {RecommendationID: "app-001", ResourceType: "Microsoft.Web/sites", Recommendation: "App Service should use TLS 1.2", Category: "Security", Impact: "High"},
+			"app-002": {RecommendationID: "app-002", ResourceType: "Microsoft.Web/sites", Recommendation: "App Service should have diagnostic settings", Category: "Monitoring and Alerting", Impact: "Low"},
+		},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: "Production",
+			ResourceGroup:    "rg",
+			Type:             "Microsoft.Web/sites",
+			ServiceName:      "app",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh, NotCompliant: true},
+			},
+		},
+	}
+	data.ResourceTypeCount = []azqr.ResourceTypeCount{
+		{Subscription: "Production", ResourceType: "Microsoft.Web/sites", Count: 3, AvailableInAPRL: "Yes"},
+	}
+	data.CostData.Items = []*scanners.CostResultItem{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", ServiceName: "Azure App Service", Value: "12.5", Currency: "EUR"},
+	}
+	return &data
+}
+
+func TestCreateNdjsonReport(t *testing.T) {
+	data := newTestData(t)
+	if err := CreateNdjsonReport(data); err != nil {
+		t.Fatalf("CreateNdjsonReport() error = %v", err)
+	}
+
+	for _, name := range []string{"recommendations", "impacted", "resource_types", "inventory", "defender", "advisor", "costs", "errors"} {
+		if _, err := os.Stat(data.OutputFileName + "." + name + ".ndjson"); err != nil {
+			t.Errorf("CreateNdjsonReport() didn't write the %s table: %v", name, err)
+		}
+	}
+
+	f, err := os.Open(data.OutputFileName + ".recommendations.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows := []map[string]interface{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		row := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("CreateNdjsonReport() wrote an invalid line: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("CreateNdjsonReport() wrote %d recommendations, want 2", len(rows))
+	}
+
+	row := rows[0]
+	if row["run_id"] != "run-1" || row["scanned_at"] != "2024-05-01T10:30:00Z" {
+		t.Errorf("CreateNdjsonReport() run = %v, %v", row["run_id"], row["scanned_at"])
+	}
+	if row["implemented"] != false || row["impacted_resources"] != float64(1) {
+		t.Errorf("CreateNdjsonReport() implemented = %v, impacted_resources = %v, want typed values", row["implemented"], row["impacted_resources"])
+	}
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	want := NewTimestamp(time.Date(2024, 5, 1, 10, 30, 0, 250000000, time.UTC))
+	js, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(js) != `"2024-05-01T10:30:00.25Z"` {
+		t.Errorf("Timestamp.MarshalJSON() = %s", js)
+	}
+
+	var got Timestamp
+	if err := json.Unmarshal(js, &got); err != nil || got != want {
+		t.Errorf("Timestamp.UnmarshalJSON() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestCreateParquetReport(t *testing.T) {
+	data := newTestData(t)
+	if err := CreateParquetReport(data); err != nil {
+		t.Fatalf("CreateParquetReport() error = %v", err)
+	}
+
+	schema := readSchema(t, data.OutputFileName+".recommendations.parquet")
+	wantColumns := "run_id,scanned_at,recommendation_id,source,resource_type,category,impact,recommendation,description,learn_more_url,implemented,impacted_resources"
+	columns := []string{}
+	for _, f := range schema.Fields() {
+		columns = append(columns, f.Name())
+	}
+	if got := strings.Join(columns, ","); got != wantColumns {
+		t.Errorf("CreateParquetReport() columns = %s, want %s", got, wantColumns)
+	}
+	if c, ok := schema.Lookup("scanned_at"); !ok || c.Node.Type().LogicalType().Timestamp == nil || c.Node.Type().LogicalType().Timestamp.Unit.Millis == nil {
+		t.Errorf("CreateParquetReport() scanned_at is not a millisecond timestamp")
+	}
+
+	recommendations, err := parquet.ReadFile[Recommendation](data.OutputFileName + ".recommendations.parquet")
+	if err != nil {
+		t.Fatalf("CreateParquetReport() wrote an invalid parquet file: %v", err)
+	}
+	if len(recommendations) != 2 {
+		t.Fatalf("CreateParquetReport() wrote %d recommendations, want 2", len(recommendations))
+	}
+	if got := recommendations[0].RecommendationID + "," + recommendations[1].RecommendationID; got != "app-001,app-002" {
+		t.Errorf("CreateParquetReport() recommendation_id = %v", got)
+	}
+	if got := recommendations[0].ScannedAt; got != NewTimestamp(data.ScannedAt) {
+		t.Errorf("CreateParquetReport() scanned_at = %v", got)
+	}
+	if got := []int64{recommendations[0].ImpactedResources, recommendations[1].ImpactedResources}; got[0] != 1 || got[1] != 0 {
+		t.Errorf("CreateParquetReport() impacted_resources = %v, want 1, 0", got)
+	}
+	if got := []bool{recommendations[0].Implemented, recommendations[1].Implemented}; got[0] || !got[1] {
+		t.Errorf("CreateParquetReport() implemented = %v, want false, true", got)
+	}
+
+	costs, err := parquet.ReadFile[Cost](data.OutputFileName + ".costs.parquet")
+	if err != nil {
+		t.Fatalf("CreateParquetReport() wrote an invalid parquet file: %v", err)
+	}
+	if got := costs[0].Value; got != 12.5 {
+		t.Errorf("CreateParquetReport() value = %v, want 12.5", got)
+	}
+	if got := costs[0].SubscriptionID; strings.Contains(got, subscriptionID) {
+		t.Errorf("CreateParquetReport() subscription_id = %s, want masked", got)
+	}
+
+	errors, err := parquet.ReadFile[Error](data.OutputFileName + ".errors.parquet")
+	if err != nil {
+		t.Fatalf("CreateParquetReport() wrote an invalid parquet file: %v", err)
+	}
+	if columns := readSchema(t, data.OutputFileName+".errors.parquet").Fields(); len(errors) != 0 || len(columns) != 6 {
+		t.Errorf("CreateParquetReport() errors rows = %d, columns = %d, want an empty table with 6 columns", len(errors), len(columns))
+	}
+}
+
+// readSchema returns the schema of a parquet file
+func readSchema(t *testing.T, name string) *parquet.Schema {
+	t.Helper()
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("%s is not a parquet file: %v", name, err)
+	}
+	return f.Schema()
+}
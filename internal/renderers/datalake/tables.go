@@ -0,0 +1,307 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package datalake
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azqr/internal/renderers"
+	jsonreport "github.com/Azure/azqr/internal/renderers/json"
+)
+
+type (
+	// Timestamp - Milliseconds since the Unix epoch, written as RFC 3339 in NDJSON and as a TIMESTAMP_MILLIS column in Parquet
+	Timestamp int64
+
+	// Run - Columns shared by the rows of every table
+	Run struct {
+		RunID     string    `json:"run_id" parquet:"run_id"`
+		ScannedAt Timestamp `json:"scanned_at" parquet:"scanned_at,timestamp(millisecond)"`
+	}
+
+	// Recommendation - Row of the recommendations table
+	Recommendation struct {
+		Run
+		RecommendationID  string `json:"recommendation_id" parquet:"recommendation_id"`
+		Source            string `json:"source" parquet:"source"`
+		ResourceType      string `json:"resource_type" parquet:"resource_type"`
+		Category          string `json:"category" parquet:"category"`
+		Impact            string `json:"impact" parquet:"impact"`
+		Recommendation    string `json:"recommendation" parquet:"recommendation"`
+		Description       string `json:"description" parquet:"description"`
+		LearnMoreURL      string `json:"learn_more_url" parquet:"learn_more_url"`
+		Implemented       bool   `json:"implemented" parquet:"implemented"`
+		ImpactedResources int64  `json:"impacted_resources" parquet:"impacted_resources"`
+	}
+
+	// Impacted - Row of the impacted resources table
+	Impacted struct {
+		Run
+		ValidatedUsing   string `json:"validated_using" parquet:"validated_using"`
+		Source           string `json:"source" parquet:"source"`
+		RecommendationID string `json:"recommendation_id" parquet:"recommendation_id"`
+		Recommendation   string `json:"recommendation" parquet:"recommendation"`
+		Category         string `json:"category" parquet:"category"`
+		Impact           string `json:"impact" parquet:"impact"`
+		ResourceType     string `json:"resource_type" parquet:"resource_type"`
+		SubscriptionID   string `json:"subscription_id" parquet:"subscription_id"`
+		SubscriptionName string `json:"subscription_name" parquet:"subscription_name"`
+		ResourceGroup    string `json:"resource_group" parquet:"resource_group"`
+		Name             string `json:"name" parquet:"name"`
+		ResourceID       string `json:"resource_id" parquet:"resource_id"`
+		Result           string `json:"result" parquet:"result"`
+		Param1           string `json:"param1" parquet:"param1"`
+		Param2           string `json:"param2" parquet:"param2"`
+		Param3           string `json:"param3" parquet:"param3"`
+		Param4           string `json:"param4" parquet:"param4"`
+		Param5           string `json:"param5" parquet:"param5"`
+		LearnMoreURL     string `json:"learn_more_url" parquet:"learn_more_url"`
+	}
+
+	// ResourceType - Row of the resource types table
+	ResourceType struct {
+		Run
+		Subscription    string `json:"subscription" parquet:"subscription"`
+		ResourceType    string `json:"resource_type" parquet:"resource_type"`
+		Resources       int64  `json:"resources" parquet:"resources"`
+		AvailableInAprl bool   `json:"available_in_aprl" parquet:"available_in_aprl"`
+	}
+
+	// Resource - Row of the inventory table
+	Resource struct {
+		Run
+		SubscriptionID string `json:"subscription_id" parquet:"subscription_id"`
+		ResourceGroup  string `json:"resource_group" parquet:"resource_group"`
+		Location       string `json:"location" parquet:"location"`
+		Type           string `json:"type" parquet:"type"`
+		Name           string `json:"name" parquet:"name"`
+		SkuName        string `json:"sku_name" parquet:"sku_name"`
+		SkuTier        string `json:"sku_tier" parquet:"sku_tier"`
+		Kind           string `json:"kind" parquet:"kind"`
+		SLA            string `json:"sla" parquet:"sla"`
+		ResourceID     string `json:"resource_id" parquet:"resource_id"`
+	}
+
+	// Defender - Row of the Defender plans table
+	Defender struct {
+		Run
+		SubscriptionID   string `json:"subscription_id" parquet:"subscription_id"`
+		SubscriptionName string `json:"subscription_name" parquet:"subscription_name"`
+		Plan             string `json:"plan" parquet:"plan"`
+		Tier             string `json:"tier" parquet:"tier"`
+		Deprecated       bool   `json:"deprecated" parquet:"deprecated"`
+	}
+
+	// Advisor - Row of the Advisor recommendations table
+	Advisor struct {
+		Run
+		SubscriptionID   string `json:"subscription_id" parquet:"subscription_id"`
+		SubscriptionName string `json:"subscription_name" parquet:"subscription_name"`
+		Type             string `json:"type" parquet:"type"`
+		Name             string `json:"name" parquet:"name"`
+		Category         string `json:"category" parquet:"category"`
+		Impact           string `json:"impact" parquet:"impact"`
+		Description      string `json:"description" parquet:"description"`
+		ResourceID       string `json:"resource_id" parquet:"resource_id"`
+		RecommendationID string `json:"recommendation_id" parquet:"recommendation_id"`
+	}
+
+	// Cost - Row of the costs table
+	Cost struct {
+		Run
+		From             Timestamp `json:"from" parquet:"from,timestamp(millisecond)"`
+		To               Timestamp `json:"to" parquet:"to,timestamp(millisecond)"`
+		SubscriptionID   string    `json:"subscription_id" parquet:"subscription_id"`
+		SubscriptionName string    `json:"subscription_name" parquet:"subscription_name"`
+		ServiceName      string    `json:"service_name" parquet:"service_name"`
+		Value            float64   `json:"value" parquet:"value"`
+		Currency         string    `json:"currency" parquet:"currency"`
+	}
+
+	// Error - Row of the errors table
+	Error struct {
+		Run
+		SubscriptionID   string `json:"subscription_id" parquet:"subscription_id"`
+		SubscriptionName string `json:"subscription_name" parquet:"subscription_name"`
+		Component        string `json:"component" parquet:"component"`
+		Message          string `json:"message" parquet:"message"`
+	}
+
+	// Tables - Rows of every table of the report data
+	Tables struct {
+		Recommendations []Recommendation
+		Impacted        []Impacted
+		ResourceTypes   []ResourceType
+		Inventory       []Resource
+		Defender        []Defender
+		Advisor         []Advisor
+		Costs           []Cost
+		Errors          []Error
+	}
+)
+
+// NewTimestamp - Converts a time to a Timestamp
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(t.UnixMilli())
+}
+
+// Time - Converts the Timestamp to a UTC time
+func (t Timestamp) Time() time.Time {
+	return time.UnixMilli(int64(t)).UTC()
+}
+
+// MarshalJSON - Writes the Timestamp as an RFC 3339 string
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Time().Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON - Reads the Timestamp from an RFC 3339 string
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	*t = NewTimestamp(v)
+	return nil
+}
+
+// NewTables - Converts the report data to typed rows. Subscription ids are masked unless --mask=false is used.
+func NewTables(data *renderers.ReportData) Tables {
+	report := jsonreport.NewReport(data)
+	run := Run{RunID: data.RunID, ScannedAt: NewTimestamp(data.ScannedAt)}
+
+	t := Tables{
+		Recommendations: []Recommendation{},
+		Impacted:        []Impacted{},
+		ResourceTypes:   []ResourceType{},
+		Inventory:       []Resource{},
+		Defender:        []Defender{},
+		Advisor:         []Advisor{},
+		Costs:           []Cost{},
+		Errors:          []Error{},
+	}
+
+	for _, r := range report.Recommendations {
+		t.Recommendations = append(t.Recommendations, Recommendation{
+			Run:               run,
+			RecommendationID:  r.RecommendationID,
+			Source:            r.Source,
+			ResourceType:      r.ResourceType,
+			Category:          r.Category,
+			Impact:            r.Impact,
+			Recommendation:    r.Recommendation,
+			Description:       r.Description,
+			LearnMoreURL:      r.LearnMoreURL,
+			Implemented:       r.Implemented,
+			ImpactedResources: int64(r.ImpactedResources),
+		})
+	}
+
+	for _, r := range report.Impacted {
+		t.Impacted = append(t.Impacted, Impacted{
+			Run:              run,
+			ValidatedUsing:   r.ValidatedUsing,
+			Source:           r.Source,
+			RecommendationID: r.RecommendationID,
+			Recommendation:   r.Recommendation,
+			Category:         r.Category,
+			Impact:           r.Impact,
+			ResourceType:     r.ResourceType,
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			ResourceGroup:    r.ResourceGroup,
+			Name:             r.Name,
+			ResourceID:       r.ID,
+			Result:           r.Result,
+			Param1:           r.Param1,
+			Param2:           r.Param2,
+			Param3:           r.Param3,
+			Param4:           r.Param4,
+			Param5:           r.Param5,
+			LearnMoreURL:     r.LearnMoreURL,
+		})
+	}
+
+	for _, r := range report.ResourceTypes {
+		t.ResourceTypes = append(t.ResourceTypes, ResourceType{
+			Run:             run,
+			Subscription:    r.Subscription,
+			ResourceType:    r.ResourceType,
+			Resources:       int64(r.Count),
+			AvailableInAprl: r.AvailableInAprl,
+		})
+	}
+
+	for _, r := range report.Inventory {
+		t.Inventory = append(t.Inventory, Resource{
+			Run:            run,
+			SubscriptionID: r.SubscriptionID,
+			ResourceGroup:  r.ResourceGroup,
+			Location:       r.Location,
+			Type:           r.Type,
+			Name:           r.Name,
+			SkuName:        r.SkuName,
+			SkuTier:        r.SkuTier,
+			Kind:           r.Kind,
+			SLA:            r.SLA,
+			ResourceID:     r.ID,
+		})
+	}
+
+	for _, r := range report.Defender {
+		t.Defender = append(t.Defender, Defender{
+			Run:              run,
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			Plan:             r.Name,
+			Tier:             r.Tier,
+			Deprecated:       r.Deprecated,
+		})
+	}
+
+	for _, r := range report.Advisor {
+		t.Advisor = append(t.Advisor, Advisor{
+			Run:              run,
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			Type:             r.Type,
+			Name:             r.Name,
+			Category:         r.Category,
+			Impact:           r.Impact,
+			Description:      r.Description,
+			ResourceID:       r.ResourceID,
+			RecommendationID: r.RecommendationID,
+		})
+	}
+
+	for _, r := range report.Costs.Items {
+		t.Costs = append(t.Costs, Cost{
+			Run:              run,
+			From:             NewTimestamp(report.Costs.From),
+			To:               NewTimestamp(report.Costs.To),
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			ServiceName:      r.ServiceName,
+			Value:            r.Value,
+			Currency:         r.Currency,
+		})
+	}
+
+	for _, r := range report.Errors {
+		t.Errors = append(t.Errors, Error{
+			Run:              run,
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			Component:        r.Component,
+			Message:          r.Message,
+		})
+	}
+
+	return t
+}
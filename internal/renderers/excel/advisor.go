@@ -4,6 +4,7 @@
 package excel
 
 import (
+	"fmt"
 	_ "image/png"
 
 	"github.com/Azure/azqr/internal/renderers"
@@ -11,15 +12,17 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-func renderAdvisor(f *excelize.File, data *renderers.ReportData) {
-	_, err := f.NewSheet("Advisor")
+func renderAdvisor(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	_, err := f.NewSheet(SheetAdvisor)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Advisor sheet")
+		return fmt.Errorf("failed to create Advisor sheet: %w", err)
 	}
 
-	records := data.AdvisorTable()
+	records := p.apply(SheetAdvisor, data.AdvisorTable())
 	headers := records[0]
-	createFirstRow(f, "Advisor", headers)
+	if err := createFirstRow(f, p, SheetAdvisor, headers); err != nil {
+		return err
+	}
 
 	if len(data.AdvisorData) > 0 {
 		records = records[1:]
@@ -28,16 +31,17 @@ func renderAdvisor(f *excelize.File, data *renderers.ReportData) {
 			currentRow += 1
 			cell, err := excelize.CoordinatesToCellName(1, currentRow)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return fmt.Errorf("failed to get cell: %w", err)
 			}
-			err = f.SetSheetRow("Advisor", cell, &row)
+			err = f.SetSheetRow(SheetAdvisor, cell, &row)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to set row")
+				return fmt.Errorf("failed to set row: %w", err)
 			}
 		}
 
-		configureSheet(f, "Advisor", headers, currentRow)
+		return configureSheet(f, p, SheetAdvisor, headers, currentRow)
 	} else {
 		log.Info().Msg("Skipping Advisor. No data to render")
+		return nil
 	}
 }
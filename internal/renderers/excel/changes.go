@@ -0,0 +1,51 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"fmt"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+func renderChanges(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	if data.Changes == nil {
+		return nil
+	}
+
+	sheetName := SheetChanges
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
+	}
+
+	records := p.apply(sheetName, data.ChangesTable())
+	headers := records[0]
+	if err := createFirstRow(f, p, sheetName, headers); err != nil {
+		return err
+	}
+
+	if len(records) > 1 {
+		records = records[1:]
+		currentRow := 4
+		for _, row := range records {
+			currentRow += 1
+			cell, err := excelize.CoordinatesToCellName(1, currentRow)
+			if err != nil {
+				return fmt.Errorf("failed to get cell: %w", err)
+			}
+			err = f.SetSheetRow(sheetName, cell, &row)
+			if err != nil {
+				return fmt.Errorf("failed to set row: %w", err)
+			}
+		}
+
+		return configureSheet(f, p, sheetName, headers, currentRow)
+	} else {
+		log.Info().Msgf("Skipping %s. No data to render", sheetName)
+		return nil
+	}
+}
@@ -4,6 +4,7 @@
 package excel
 
 import (
+	"fmt"
 	_ "image/png"
 
 	"github.com/Azure/azqr/internal/renderers"
@@ -11,15 +12,17 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-func renderCosts(f *excelize.File, data *renderers.ReportData) {
-	_, err := f.NewSheet("Costs")
+func renderCosts(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	_, err := f.NewSheet(SheetCosts)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Costs sheet")
+		return fmt.Errorf("failed to create Costs sheet: %w", err)
 	}
 
-	records := data.CostTable()
+	records := p.apply(SheetCosts, data.CostTable())
 	headers := records[0]
-	createFirstRow(f, "Costs", headers)
+	if err := createFirstRow(f, p, SheetCosts, headers); err != nil {
+		return err
+	}
 	
 	if data.CostData != nil && len(data.CostData.Items) > 0 {
 		records = records[1:]
@@ -28,16 +31,17 @@ func renderCosts(f *excelize.File, data *renderers.ReportData) {
 			currentRow += 1
 			cell, err := excelize.CoordinatesToCellName(1, currentRow)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return fmt.Errorf("failed to get cell: %w", err)
 			}
-			err = f.SetSheetRow("Costs", cell, &row)
+			err = f.SetSheetRow(SheetCosts, cell, &row)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to set row")
+				return fmt.Errorf("failed to set row: %w", err)
 			}
 		}
 
-		configureSheet(f, "Costs", headers, currentRow)
+		return configureSheet(f, p, SheetCosts, headers, currentRow)
 	} else {
 		log.Info().Msg("Skipping Costs. No data to render")
+		return nil
 	}
 }
@@ -4,6 +4,7 @@
 package excel
 
 import (
+	"fmt"
 	_ "image/png"
 
 	"github.com/Azure/azqr/internal/renderers"
@@ -11,15 +12,17 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-func renderDefender(f *excelize.File, data *renderers.ReportData) {
-	_, err := f.NewSheet("Defender")
+func renderDefender(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	_, err := f.NewSheet(SheetDefender)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Defender sheet")
+		return fmt.Errorf("failed to create Defender sheet: %w", err)
 	}
 
-	records := data.DefenderTable()
+	records := p.apply(SheetDefender, data.DefenderTable())
 	headers := records[0]
-	createFirstRow(f, "Defender", headers)
+	if err := createFirstRow(f, p, SheetDefender, headers); err != nil {
+		return err
+	}
 
 	if len(data.DefenderData) > 0 {
 		records = records[1:]
@@ -28,16 +31,17 @@ func renderDefender(f *excelize.File, data *renderers.ReportData) {
 			currentRow += 1
 			cell, err := excelize.CoordinatesToCellName(1, currentRow)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return fmt.Errorf("failed to get cell: %w", err)
 			}
-			err = f.SetSheetRow("Defender", cell, &row)
+			err = f.SetSheetRow(SheetDefender, cell, &row)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to set row")
+				return fmt.Errorf("failed to set row: %w", err)
 			}
 		}
 
-		configureSheet(f, "Defender", headers, currentRow)
+		return configureSheet(f, p, SheetDefender, headers, currentRow)
 	} else {
 		log.Info().Msg("Skipping Defender. No data to render")
+		return nil
 	}
 }
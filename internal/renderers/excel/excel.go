@@ -5,36 +5,66 @@ package excel
 
 import (
 	"fmt"
+	_ "image/gif"
+	_ "image/jpeg"
 	_ "image/png"
 
-	"github.com/Azure/azqr/internal/embeded"
 	"github.com/Azure/azqr/internal/renderers"
 	"github.com/rs/zerolog/log"
 	"github.com/xuri/excelize/v2"
 )
 
-func CreateExcelReport(data *renderers.ReportData) {
+// CreateExcelReport - Writes the excel report to <OutputFileName>.xlsx with the sheets, columns, colors and logo of the profile
+func CreateExcelReport(data *renderers.ReportData, profile *Profile) (err error) {
 	filename := fmt.Sprintf("%s.xlsx", data.OutputFileName)
 	log.Info().Msgf("Generating Report: %s", filename)
 	f := excelize.NewFile()
 	defer func() {
-		if err := f.Close(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to close Excel file")
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close Excel file: %w", cerr)
 		}
 	}()
 
-	lastRow := renderRecommendations(f, data)
-	renderImpactedResources(f, data)
-	renderResourceTypes(f, data)
-	renderResources(f, data)
-	renderAdvisor(f, data)
-	renderDefender(f, data)
-	renderCosts(f, data)
-	renderRecommendationsPivotTables(f, lastRow)
+	sheets := map[string]func(*excelize.File, *renderers.ReportData, *Profile) error{
+		SheetOverview:          renderOverview,
+		SheetImpactedResources: renderImpactedResources,
+		SheetSuppressed:        renderSuppressed,
+		SheetResourceTypes:     renderResourceTypes,
+		SheetInventory:         renderResources,
+		SheetAdvisor:           renderAdvisor,
+		SheetDefender:          renderDefender,
+		SheetCosts:             renderCosts,
+		SheetChanges:           renderChanges,
+		SheetErrors:            renderErrors,
+	}
+
+	lastRow := 0
+	for _, s := range profile.Sheets {
+		switch s.Name {
+		case SheetRecommendations:
+			lastRow, err = renderRecommendations(f, data, profile)
+		case SheetPivotTable:
+			err = renderRecommendationsPivotTables(f, profile, lastRow)
+		default:
+			err = sheets[s.Name](f, data, profile)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// every sheet is created with NewSheet, so the default one is only kept if nothing was rendered
+	if f.SheetCount > 1 {
+		if err := f.DeleteSheet("Sheet1"); err != nil {
+			return fmt.Errorf("failed to delete default sheet: %w", err)
+		}
+		f.SetActiveSheet(0)
+	}
 
 	if err := f.SaveAs(filename); err != nil {
-		log.Fatal().Err(err).Msg("Failed to save Excel file")
+		return fmt.Errorf("failed to save Excel file: %w", err)
 	}
+	return nil
 }
 
 func autofit(f *excelize.File, sheetName string) error {
@@ -67,15 +97,19 @@ func autofit(f *excelize.File, sheetName string) error {
 	return nil
 }
 
-func createFirstRow(f *excelize.File, sheet string, headers []string) {
-	currentRow := 4
-	cell, err := excelize.CoordinatesToCellName(1, currentRow)
+func createFirstRow(f *excelize.File, p *Profile, sheet string, headers []string) error {
+	return createHeaderRow(f, p, sheet, 4, headers)
+}
+
+// createHeaderRow writes the headers in the given row with the header style of the profile
+func createHeaderRow(f *excelize.File, p *Profile, sheet string, row int, headers []string) error {
+	cell, err := excelize.CoordinatesToCellName(1, row)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to get cell")
+		return fmt.Errorf("failed to get cell: %w", err)
 	}
 	err = f.SetSheetRow(sheet, cell, &headers)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to set row")
+		return fmt.Errorf("failed to set row: %w", err)
 	}
 
 	style, err := f.NewStyle(&excelize.Style{
@@ -84,30 +118,36 @@ func createFirstRow(f *excelize.File, sheet string, headers []string) {
 		},
 		Fill: excelize.Fill{
 			Type:    "pattern",
-			Color:   []string{"#CAEDFB"},
+			Color:   []string{p.HeaderColor},
 			Pattern: 1,
 		},
 	})
 
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create style")
+		return fmt.Errorf("failed to create style: %w", err)
 	}
 
 	for j := 1; j <= len(headers); j++ {
-		cell, err := excelize.CoordinatesToCellName(j, 4)
+		cell, err := excelize.CoordinatesToCellName(j, row)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to get cell")
+			return fmt.Errorf("failed to get cell: %w", err)
 		}
 
 		err = f.SetCellStyle(sheet, cell, cell, style)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to set style")
+			return fmt.Errorf("failed to set style: %w", err)
 		}
 	}
+	return nil
 }
 
-func setHyperLink(f *excelize.File, sheet string, col, currentRow int) {
-	cell, _ := excelize.CoordinatesToCellName(col, currentRow)
+// setHyperLink turns the url in the given column of the row into a link. Nothing is done if the column isn't rendered.
+func setHyperLink(f *excelize.File, sheet string, headers []string, column string, currentRow int) {
+	col := columnIndex(headers, column)
+	if col < 0 {
+		return
+	}
+	cell, _ := excelize.CoordinatesToCellName(col+1, currentRow)
 	link, _ := f.GetCellValue(sheet, cell)
 	display := link
 	tooltip := "Learn more..."
@@ -117,43 +157,50 @@ func setHyperLink(f *excelize.File, sheet string, col, currentRow int) {
 	}
 }
 
-func configureSheet(f *excelize.File, sheet string, headers []string, currentRow int) {
+func configureSheet(f *excelize.File, p *Profile, sheet string, headers []string, currentRow int) error {
 	_ = autofit(f, sheet)
 
 	cell, err := excelize.CoordinatesToCellName(len(headers), currentRow)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to get cell")
+		return fmt.Errorf("failed to get cell: %w", err)
 	}
 	err = f.AutoFilter(sheet, fmt.Sprintf("A4:%s", cell), nil)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to set autofilter")
+		return fmt.Errorf("failed to set autofilter: %w", err)
+	}
+
+	if err := addLogo(f, p, sheet); err != nil {
+		return err
 	}
 
-	logo := embeded.GetTemplates("microsoft.png")
+	return applyBlueStyle(f, p, sheet, currentRow, len(headers))
+}
+
+// addLogo adds the logo of the profile to the top left corner of the sheet
+func addLogo(f *excelize.File, p *Profile, sheet string) error {
 	opt := &excelize.GraphicOptions{
 		ScaleX:      1,
 		ScaleY:      1,
 		Positioning: "absolute",
-		AltText:     "Azure Logo",
+		AltText:     "Logo",
 	}
 	pic := &excelize.Picture{
-		Extension: ".png",
-		File:      logo,
+		Extension: p.logoExtension,
+		File:      p.logo,
 		Format:    opt,
 	}
 
 	if err := f.AddPictureFromBytes(sheet, "A1", pic); err != nil {
-		log.Fatal().Err(err).Msg("Failed to add logo")
+		return fmt.Errorf("failed to add logo: %w", err)
 	}
-
-	applyBlueStyle(f, sheet, currentRow, len(headers))
+	return nil
 }
 
-func applyBlueStyle(f *excelize.File, sheet string, lastRow int, columns int) {
+func applyBlueStyle(f *excelize.File, p *Profile, sheet string, lastRow int, columns int) error {
 	blue, err := f.NewStyle(&excelize.Style{
 		Fill: excelize.Fill{
 			Type:    "pattern",
-			Color:   []string{"#CAEDFB"},
+			Color:   []string{p.RowColor},
 			Pattern: 1,
 		},
 		Alignment: &excelize.Alignment{
@@ -162,7 +209,7 @@ func applyBlueStyle(f *excelize.File, sheet string, lastRow int, columns int) {
 		},
 	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create blue style")
+		return fmt.Errorf("failed to create blue style: %w", err)
 	}
 	white, err := f.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{
@@ -171,27 +218,28 @@ func applyBlueStyle(f *excelize.File, sheet string, lastRow int, columns int) {
 		},
 	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create blue style")
+		return fmt.Errorf("failed to create white style: %w", err)
 	}
 
 	for i := 5; i <= lastRow; i++ {
 		for j := 1; j <= columns; j++ {
 			cell, err := excelize.CoordinatesToCellName(j, i)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return fmt.Errorf("failed to get cell: %w", err)
 			}
 
 			if i%2 == 0 {
 				err = f.SetCellStyle(sheet, cell, cell, blue)
 				if err != nil {
-					log.Fatal().Err(err).Msg("Failed to set style")
+					return fmt.Errorf("failed to set style: %w", err)
 				}
 			} else {
 				err = f.SetCellStyle(sheet, cell, cell, white)
 				if err != nil {
-					log.Fatal().Err(err).Msg("Failed to set style")
+					return fmt.Errorf("failed to set style: %w", err)
 				}
 			}
 		}
 	}
+	return nil
 }
@@ -4,6 +4,7 @@
 package excel
 
 import (
+	"fmt"
 	_ "image/png"
 
 	"github.com/Azure/azqr/internal/renderers"
@@ -11,16 +12,18 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-func renderImpactedResources(f *excelize.File, data *renderers.ReportData) {
-	sheetName := "ImpactedResources"
+func renderImpactedResources(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	sheetName := SheetImpactedResources
 	_, err := f.NewSheet(sheetName)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create APRL sheet")
+		return fmt.Errorf("failed to create APRL sheet: %w", err)
 	}
 
-	records := data.ImpactedTable()
+	records := p.apply(sheetName, data.ImpactedTable())
 	headers := records[0]
-	createFirstRow(f, sheetName, headers)
+	if err := createFirstRow(f, p, sheetName, headers); err != nil {
+		return err
+	}
 
 	if len(records) > 0 {
 		records = records[1:]
@@ -29,17 +32,18 @@ func renderImpactedResources(f *excelize.File, data *renderers.ReportData) {
 			currentRow += 1
 			cell, err := excelize.CoordinatesToCellName(1, currentRow)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return fmt.Errorf("failed to get cell: %w", err)
 			}
 			err = f.SetSheetRow(sheetName, cell, &row)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to set row")
+				return fmt.Errorf("failed to set row: %w", err)
 			}
-			setHyperLink(f, sheetName, 18, currentRow)
+			setHyperLink(f, sheetName, headers, "Learn", currentRow)
 		}
 
-		configureSheet(f, sheetName, headers, currentRow)
+		return configureSheet(f, p, sheetName, headers, currentRow)
 	} else {
 		log.Info().Msgf("Skipping %s. No data to render", sheetName)
+		return nil
 	}
 }
@@ -0,0 +1,304 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+// topResourceTypes - Number of resource types in the overview chart
+const topResourceTypes = 10
+
+type (
+	// overviewBlock is a table of the overview sheet and the chart drawn from it.
+	// The first column holds the categories and the next columns the series of the chart.
+	overviewBlock struct {
+		title   string
+		headers []string
+		rows    [][]interface{}
+		chart   excelize.ChartType
+		series  int
+	}
+
+	// count is the number of rows of the given name
+	count struct {
+		name  string
+		count int
+	}
+)
+
+// renderOverview renders the headline numbers of the report and the charts of the findings, Defender plans and costs.
+// Everything is computed from the same tables as the other sheets, so the overview always matches them.
+func renderOverview(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	sheetName := SheetOverview
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
+	}
+
+	recommendations := data.RecommendationsTable()
+	impacted := data.ImpactedTable()
+
+	implemented := 0
+	col := columnIndex(recommendations[0], "Implemented")
+	for _, r := range recommendations[1:] {
+		if r[col] == "true" {
+			implemented++
+		}
+	}
+	percentage := 0.0
+	if len(recommendations) > 1 {
+		percentage = float64(implemented) / float64(len(recommendations)-1)
+	}
+
+	headline := overviewBlock{
+		headers: []string{"Overview", "Value"},
+		rows: [][]interface{}{
+			{"Resources", len(data.ResourcesTable()) - 1},
+			{"Recommendations", len(recommendations) - 1},
+			{"Implemented Recommendations", implemented},
+			{"Implemented (%)", percentage},
+			{"Impacted Resources", len(impacted) - 1},
+		},
+	}
+	lastRow, err := writeOverviewBlock(f, p, sheetName, 4, headline)
+	if err != nil {
+		return err
+	}
+
+	percent, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return fmt.Errorf("failed to create percent style: %w", err)
+	}
+	if err := f.SetCellStyle(sheetName, "B8", "B8", percent); err != nil {
+		return fmt.Errorf("failed to set style: %w", err)
+	}
+
+	blocks := []overviewBlock{
+		countBlock("Findings by Impact", "Impact", impactCounts(impacted), excelize.Pie),
+		countBlock("Findings by Category", "Category", countBy(impacted, "Category", 0), excelize.Bar),
+		countBlock(fmt.Sprintf("Top %d Resource Types by Impacted Resources", topResourceTypes), "Resource Type",
+			countBy(impacted, "Resource Type", topResourceTypes), excelize.Bar),
+		defenderBlock(data.DefenderTable()),
+		costBlock(data),
+	}
+
+	currentRow := lastRow + 3
+	for _, b := range blocks {
+		if len(b.rows) == 0 {
+			log.Info().Msgf("Skipping %s chart. No data to render", b.title)
+			continue
+		}
+
+		firstRow := currentRow
+		lastRow, err := writeOverviewBlock(f, p, sheetName, firstRow, b)
+		if err != nil {
+			return err
+		}
+
+		if err := addOverviewChart(f, sheetName, firstRow, lastRow, b); err != nil {
+			return err
+		}
+
+		// leave room for the chart, which is taller than most tables
+		currentRow = max(lastRow, firstRow+16) + 2
+	}
+
+	_ = autofit(f, sheetName)
+	return addLogo(f, p, sheetName)
+}
+
+// writeOverviewBlock writes the headers and rows of the block from the given row and returns the last row written
+func writeOverviewBlock(f *excelize.File, p *Profile, sheet string, row int, b overviewBlock) (int, error) {
+	if err := createHeaderRow(f, p, sheet, row, b.headers); err != nil {
+		return 0, err
+	}
+	for _, r := range b.rows {
+		row++
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get cell: %w", err)
+		}
+		if err := f.SetSheetRow(sheet, cell, &r); err != nil {
+			return 0, fmt.Errorf("failed to set row: %w", err)
+		}
+	}
+	return row, nil
+}
+
+// addOverviewChart draws the chart of the block to the right of its table
+func addOverviewChart(f *excelize.File, sheet string, firstRow, lastRow int, b overviewBlock) error {
+	series := []excelize.ChartSeries{}
+	for i := 1; i <= b.series; i++ {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		series = append(series, excelize.ChartSeries{
+			Name:       fmt.Sprintf("%s!$%s$%d", sheet, column, firstRow),
+			Categories: fmt.Sprintf("%s!$A$%d:$A$%d", sheet, firstRow+1, lastRow),
+			Values:     fmt.Sprintf("%s!$%s$%d:$%s$%d", sheet, column, firstRow+1, column, lastRow),
+		})
+	}
+
+	chart := &excelize.Chart{
+		Type:      b.chart,
+		Series:    series,
+		Title:     []excelize.RichTextRun{{Text: b.title}},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 320},
+		Legend:    excelize.ChartLegend{Position: "bottom"},
+		PlotArea:  excelize.ChartPlotArea{ShowVal: b.chart != excelize.Pie, ShowPercent: b.chart == excelize.Pie},
+	}
+	if len(series) == 1 && b.chart != excelize.Pie {
+		chart.Legend.Position = "none"
+	}
+
+	if err := f.AddChart(sheet, fmt.Sprintf("F%d", firstRow), chart); err != nil {
+		return fmt.Errorf("failed to add %s chart: %w", b.title, err)
+	}
+	return nil
+}
+
+// countBlock returns a block with the name and number of findings of each entry
+func countBlock(title, header string, counts []count, chart excelize.ChartType) overviewBlock {
+	b := overviewBlock{title: title, headers: []string{header, "Findings"}, chart: chart, series: 1}
+	for _, c := range counts {
+		b.rows = append(b.rows, []interface{}{c.name, c.count})
+	}
+	return b
+}
+
+// impactCounts returns the number of findings of each impact, from High to Low
+func impactCounts(impacted [][]string) []count {
+	counts := map[string]int{}
+	for _, c := range countBy(impacted, "Impact", 0) {
+		counts[strings.ToLower(c.name)] += c.count
+	}
+
+	r := []count{}
+	for _, i := range []string{"High", "Medium", "Low"} {
+		if counts[strings.ToLower(i)] > 0 {
+			r = append(r, count{name: i, count: counts[strings.ToLower(i)]})
+		}
+	}
+	return r
+}
+
+// countBy returns the n values of the column with the most rows, or every value if n is 0.
+// Values are compared ignoring case and named after their first occurrence.
+func countBy(records [][]string, column string, n int) []count {
+	col := columnIndex(records[0], column)
+	counts := map[string]*count{}
+	for _, r := range records[1:] {
+		key := strings.ToLower(r[col])
+		if _, ok := counts[key]; !ok {
+			counts[key] = &count{name: r[col]}
+		}
+		counts[key].count++
+	}
+
+	result := []count{}
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].count != result[j].count {
+			return result[i].count > result[j].count
+		}
+		return result[i].name < result[j].name
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// defenderBlock returns the number of Defender plans of each subscription on the Standard and Free tiers.
+// Deprecated plans are ignored.
+func defenderBlock(records [][]string) overviewBlock {
+	b := overviewBlock{
+		title:   "Defender Coverage by Subscription",
+		headers: []string{"Subscription", "Standard", "Free"},
+		chart:   excelize.BarStacked,
+		series:  2,
+	}
+
+	headers := records[0]
+	name, tier, deprecated := columnIndex(headers, "Subscription Name"), columnIndex(headers, "Tier"), columnIndex(headers, "Deprecated")
+	plans := map[string][]int{}
+	subscriptions := []string{}
+	for _, r := range records[1:] {
+		if r[deprecated] == "true" {
+			continue
+		}
+		if _, ok := plans[r[name]]; !ok {
+			plans[r[name]] = []int{0, 0}
+			subscriptions = append(subscriptions, r[name])
+		}
+		if strings.EqualFold(r[tier], "Free") {
+			plans[r[name]][1]++
+		} else {
+			plans[r[name]][0]++
+		}
+	}
+
+	sort.Strings(subscriptions)
+	for _, s := range subscriptions {
+		b.rows = append(b.rows, []interface{}{s, plans[s][0], plans[s][1]})
+	}
+	return b
+}
+
+// costBlock returns the cost of each service across all subscriptions, from the highest to the lowest
+func costBlock(data *renderers.ReportData) overviewBlock {
+	records := data.CostTable()
+	b := overviewBlock{
+		title: fmt.Sprintf("Cost by Service (%s - %s)",
+			data.CostData.From.Format("2006-01-02"), data.CostData.To.Format("2006-01-02")),
+		headers: []string{"Service", "Cost", "Currency"},
+		chart:   excelize.Bar,
+		series:  1,
+	}
+
+	headers := records[0]
+	service, value, currency := columnIndex(headers, "ServiceName"), columnIndex(headers, "Value"), columnIndex(headers, "Currency")
+	type cost struct {
+		service, currency string
+		value             float64
+	}
+	costs := map[string]*cost{}
+	for _, r := range records[1:] {
+		v, err := strconv.ParseFloat(r[value], 64)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping cost of %s", r[service])
+			continue
+		}
+		key := r[service] + "|" + r[currency]
+		if _, ok := costs[key]; !ok {
+			costs[key] = &cost{service: r[service], currency: r[currency]}
+		}
+		costs[key].value += v
+	}
+
+	sorted := []*cost{}
+	for _, c := range costs {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].value != sorted[j].value {
+			return sorted[i].value > sorted[j].value
+		}
+		return sorted[i].service < sorted[j].service
+	})
+	for _, c := range sorted {
+		b.rows = append(b.rows, []interface{}{c.service, c.value, c.currency})
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/scanners"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRenderOverview(t *testing.T) {
+	data := newTestData(t)
+	data.AprlData = []azqr.AprlResult{
+		{RecommendationID: "st-002", ResourceType: "microsoft.storage/storageaccounts", Impact: azqr.ImpactHigh, Category: azqr.CategoryHighAvailability},
+	}
+	data.Resources = []*azqr.Resource{{ID: "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st"}}
+	data.DefenderData = []scanners.DefenderResult{
+		{SubscriptionName: "Production", Name: "VirtualMachines", Tier: "Standard"},
+		{SubscriptionName: "Production", Name: "StorageAccounts", Tier: "Free"},
+		{SubscriptionName: "Production", Name: "KubernetesService", Tier: "Free", Deprecated: true},
+	}
+	data.CostData.Items = []*scanners.CostResultItem{
+		{ServiceName: "Storage", Value: "1.5", Currency: "EUR"},
+		{ServiceName: "Storage", Value: "2", Currency: "EUR"},
+		{ServiceName: "Compute", Value: "10", Currency: "EUR"},
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := renderOverview(f, data, DefaultProfile()); err != nil {
+		t.Fatalf("renderOverview() error = %v", err)
+	}
+
+	rows, err := f.GetRows(SheetOverview, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"Overview", "Value"},
+		{"Resources", "1"},
+		{"Recommendations", "2"},
+		{"Implemented Recommendations", "0"},
+		{"Implemented (%)", "0"},
+		{"Impacted Resources", "2"},
+	}
+	if got := rows[3:9]; !reflect.DeepEqual(got, want) {
+		t.Errorf("renderOverview() headline = %v, want %v", got, want)
+	}
+
+	blocks := map[string][][]string{}
+	for i, r := range rows {
+		if len(r) > 1 && (r[1] == "Findings" || r[1] == "Standard" || r[1] == "Cost") {
+			block := [][]string{}
+			for _, row := range rows[i+1:] {
+				if len(row) == 0 {
+					break
+				}
+				block = append(block, row)
+			}
+			blocks[r[0]] = block
+		}
+	}
+
+	tests := map[string][][]string{
+		"Impact":        {{"High", "1"}, {"Low", "1"}},
+		"Category":      {{"High Availability", "1"}, {"Security", "1"}},
+		"Resource Type": {{"microsoft.storage/storageaccounts", "2"}},
+		"Subscription":  {{"Production", "1", "1"}},
+		"Service":       {{"Compute", "10", "EUR"}, {"Storage", "3.5", "EUR"}},
+	}
+	for header, want := range tests {
+		if got := blocks[header]; !reflect.DeepEqual(got, want) {
+			t.Errorf("renderOverview() %s = %v, want %v", header, got, want)
+		}
+	}
+}
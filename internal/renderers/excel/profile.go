@@ -0,0 +1,307 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/embeded"
+	"github.com/Azure/azqr/internal/renderers"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the sheets of the excel report
+const (
+	SheetOverview          = "Overview"
+	SheetRecommendations   = "Recommendations"
+	SheetImpactedResources = "ImpactedResources"
+	SheetSuppressed        = "Suppressed"
+	SheetResourceTypes     = "ResourceTypes"
+	SheetInventory         = "Inventory"
+	SheetAdvisor           = "Advisor"
+	SheetDefender          = "Defender"
+	SheetCosts             = "Costs"
+	SheetChanges           = "Changes"
+	SheetErrors            = "Errors"
+	SheetPivotTable        = "PivotTable"
+)
+
+const defaultColor = "#CAEDFB"
+
+var colorRegex = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// pivotColumns are the columns of the Recommendations sheet used by the pivot tables
+var pivotColumns = []string{"Implemented", "Azure Service / Well-Architected", "Azure Service / Well-Architected Topic", "Resiliency Category", "Impact"}
+
+type (
+	// Profile - Layout of the excel report: the sheets, their columns and sort order, the colors and the logo
+	Profile struct {
+		// Logo is the path of a png, jpg or gif image, relative to the profile file. The Microsoft logo is used if empty.
+		Logo string `yaml:"logo"`
+		// HeaderColor is the fill color of the header rows and RowColor the fill color of every other row, in the #RRGGBB format
+		HeaderColor string `yaml:"headerColor"`
+		RowColor    string `yaml:"rowColor"`
+		// Sheets are rendered in the given order. Sheets that are not listed are not rendered.
+		Sheets []SheetProfile `yaml:"sheets"`
+
+		logo          []byte
+		logoExtension string
+	}
+
+	// SheetProfile - Columns and sort order of a sheet
+	SheetProfile struct {
+		Name string `yaml:"name"`
+		// Columns are the headers of the columns to render, in order. Every column is rendered if empty.
+		Columns []string  `yaml:"columns"`
+		Sort    []SortKey `yaml:"sort"`
+	}
+
+	// SortKey - Column used to sort the rows of a sheet. Impacts are sorted by severity and numbers by value.
+	SortKey struct {
+		Column     string `yaml:"column"`
+		Descending bool   `yaml:"descending"`
+	}
+)
+
+// DefaultProfile - Returns the default layout of the excel report
+func DefaultProfile() *Profile {
+	p := &Profile{
+		HeaderColor:   defaultColor,
+		RowColor:      defaultColor,
+		Sheets:        []SheetProfile{},
+		logo:          embeded.GetTemplates("microsoft.png"),
+		logoExtension: ".png",
+	}
+	for _, name := range []string{SheetOverview, SheetRecommendations, SheetImpactedResources, SheetSuppressed, SheetResourceTypes,
+		SheetInventory, SheetAdvisor, SheetDefender, SheetCosts, SheetChanges, SheetErrors, SheetPivotTable} {
+		p.Sheets = append(p.Sheets, SheetProfile{Name: name})
+	}
+	return p
+}
+
+// LoadProfile - Reads the profile from a YAML file, or returns the default profile if empty.
+// Settings that are not in the file keep their default values.
+func LoadProfile(profileFile string) (*Profile, error) {
+	p := DefaultProfile()
+	if profileFile == "" {
+		return p, nil
+	}
+
+	content, err := os.ReadFile(profileFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading excel profile: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("error parsing excel profile %s: %w", profileFile, err)
+	}
+
+	if p.Logo != "" {
+		logo := p.Logo
+		if !filepath.IsAbs(logo) {
+			logo = filepath.Join(filepath.Dir(profileFile), logo)
+		}
+
+		p.logoExtension = strings.ToLower(filepath.Ext(logo))
+		switch p.logoExtension {
+		case ".png", ".jpg", ".jpeg", ".gif":
+		default:
+			return nil, fmt.Errorf("excel profile logo %s must be a png, jpg or gif image", p.Logo)
+		}
+
+		if p.logo, err = os.ReadFile(logo); err != nil {
+			return nil, fmt.Errorf("error reading excel profile logo: %w", err)
+		}
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid excel profile %s: %w", profileFile, err)
+	}
+	return p, nil
+}
+
+func (p *Profile) validate() error {
+	for _, c := range []string{p.HeaderColor, p.RowColor} {
+		if !colorRegex.MatchString(c) {
+			return fmt.Errorf("color %s must use the #RRGGBB format", c)
+		}
+	}
+
+	if len(p.Sheets) == 0 {
+		return fmt.Errorf("at least one sheet is required")
+	}
+
+	seen := map[string]bool{}
+	for _, s := range p.Sheets {
+		if seen[s.Name] {
+			return fmt.Errorf("sheet %s is listed more than once", s.Name)
+		}
+		seen[s.Name] = true
+
+		if s.Name == SheetOverview {
+			if len(s.Columns) > 0 || len(s.Sort) > 0 {
+				return fmt.Errorf("sheet %s doesn't have columns", SheetOverview)
+			}
+			continue
+		}
+
+		if s.Name == SheetPivotTable {
+			if !seen[SheetRecommendations] {
+				return fmt.Errorf("sheet %s must be listed after the %s sheet", SheetPivotTable, SheetRecommendations)
+			}
+			if len(s.Columns) > 0 || len(s.Sort) > 0 {
+				return fmt.Errorf("sheet %s doesn't have columns", SheetPivotTable)
+			}
+			if columns := p.sheet(SheetRecommendations).Columns; len(columns) > 0 {
+				for _, c := range pivotColumns {
+					if columnIndex(columns, c) < 0 {
+						return fmt.Errorf("sheet %s requires the %s column of the %s sheet", SheetPivotTable, c, SheetRecommendations)
+					}
+				}
+			}
+			continue
+		}
+
+		headers, ok := tableHeaders(s.Name)
+		if !ok {
+			return fmt.Errorf("unknown sheet %s", s.Name)
+		}
+
+		for _, c := range s.Columns {
+			if columnIndex(headers, c) < 0 {
+				return fmt.Errorf("unknown column %s of sheet %s. Valid columns are: %s", c, s.Name, strings.Join(headers, ", "))
+			}
+		}
+		for _, k := range s.Sort {
+			if columnIndex(headers, k.Column) < 0 {
+				return fmt.Errorf("unknown sort column %s of sheet %s", k.Column, s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// tableHeaders returns the headers of the table rendered in the given sheet
+func tableHeaders(sheet string) ([]string, bool) {
+	data := renderers.NewReportData("", true)
+	tables := map[string]func() [][]string{
+		SheetRecommendations:   data.RecommendationsTable,
+		SheetImpactedResources: data.ImpactedTable,
+		SheetSuppressed:        data.SuppressedTable,
+		SheetResourceTypes:     data.ResourceTypesTable,
+		SheetInventory:         data.ResourcesTable,
+		SheetAdvisor:           data.AdvisorTable,
+		SheetDefender:          data.DefenderTable,
+		SheetCosts:             data.CostTable,
+		SheetChanges:           data.ChangesTable,
+		SheetErrors:            data.ErrorsTable,
+	}
+	table, ok := tables[sheet]
+	if !ok {
+		return nil, false
+	}
+	return table()[0], true
+}
+
+// sheet returns the profile of the given sheet
+func (p *Profile) sheet(name string) SheetProfile {
+	for _, s := range p.Sheets {
+		if s.Name == name {
+			return s
+		}
+	}
+	return SheetProfile{Name: name}
+}
+
+// headers returns the headers of the columns rendered in the sheet
+func (p *Profile) headers(sheet string) []string {
+	headers, _ := tableHeaders(sheet)
+	return p.apply(sheet, [][]string{headers})[0]
+}
+
+// apply returns the records of a table, headers included, with the columns and sort order of the sheet
+func (p *Profile) apply(sheet string, records [][]string) [][]string {
+	s := p.sheet(sheet)
+	headers, rows := records[0], records[1:]
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range s.Sort {
+			c := columnIndex(headers, k.Column)
+			if cmp := compareValues(rows[i][c], rows[j][c]); cmp != 0 {
+				return (cmp < 0) != k.Descending
+			}
+		}
+		return false
+	})
+
+	if len(s.Columns) == 0 {
+		return records
+	}
+
+	indexes := make([]int, len(s.Columns))
+	for i, c := range s.Columns {
+		indexes[i] = columnIndex(headers, c)
+	}
+
+	result := make([][]string, 0, len(records))
+	for _, r := range records {
+		row := make([]string, len(indexes))
+		for i, c := range indexes {
+			row[i] = r[c]
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+// columnIndex returns the index of the column with the given header, ignoring case, or -1
+func columnIndex(headers []string, column string) int {
+	for i, h := range headers {
+		if strings.EqualFold(h, column) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compareValues compares impacts by severity, numbers by value and everything else as text
+func compareValues(a, b string) int {
+	if ia, err := azqr.ParseImpact(a); err == nil {
+		if ib, err := azqr.ParseImpact(b); err == nil {
+			switch {
+			case ia == ib:
+				return 0
+			case ia.AtLeast(ib):
+				return 1
+			default:
+				return -1
+			}
+		}
+	}
+
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/embeded"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/xuri/excelize/v2"
+)
+
+func newTestData(t *testing.T) *renderers.ReportData {
+	data := renderers.NewReportData(filepath.Join(t.TempDir(), "report"), true)
+	data.Recomendations = map[string]map[string]azqr.AprlRecommendation{
+		"microsoft.storage/storageaccounts": {
+			"st-001": {RecommendationID: "st-001", ResourceType: "Microsoft.Storage/storageAccounts", Impact: "Low", Category: "Security",
+				Recommendation: "Use private endpoints", LearnMoreLink: []struct {
+					Name string `yaml:"name"`
+					Url  string `yaml:"url"`
+				}{{Name: "Learn", Url: "https://learn.microsoft.com/st-001"}}},
+			"st-002": {RecommendationID: "st-002", ResourceType: "Microsoft.Storage/storageAccounts", Impact: "High", Category: "High Availability",
+				Recommendation: "Use ZRS", LearnMoreLink: []struct {
+					Name string `yaml:"name"`
+					Url  string `yaml:"url"`
+				}{{Name: "Learn", Url: "https://learn.microsoft.com/st-002"}}},
+		},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID: "00000000-0000-0000-0000-000000000001", SubscriptionName: "Production", ResourceGroup: "rg", ServiceName: "st",
+			Type: "Microsoft.Storage/storageAccounts",
+			Recommendations: map[string]azqr.AzqrResult{
+				"st-001": {RecommendationID: "st-001", Impact: azqr.ImpactLow, Category: azqr.CategorySecurity, NotCompliant: true},
+			},
+		},
+	}
+	return &data
+}
+
+func writeProfile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "profile.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadProfile(t *testing.T) {
+	p, err := LoadProfile("")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if !reflect.DeepEqual(p, DefaultProfile()) {
+		t.Errorf("LoadProfile() = %v, want the default profile", p)
+	}
+
+	file := writeProfile(t, `
+headerColor: "#FF0000"
+sheets:
+  - name: Recommendations
+    columns: [Recommendation Id, Impact, Read More]
+    sort:
+      - column: impact
+        descending: true
+`)
+	p, err = LoadProfile(file)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if p.HeaderColor != "#FF0000" || p.RowColor != defaultColor {
+		t.Errorf("LoadProfile() colors = %s %s", p.HeaderColor, p.RowColor)
+	}
+	if len(p.Sheets) != 1 || p.Sheets[0].Sort[0].Column != "impact" {
+		t.Errorf("LoadProfile() sheets = %v", p.Sheets)
+	}
+	if !reflect.DeepEqual(p.logo, embeded.GetTemplates("microsoft.png")) {
+		t.Errorf("LoadProfile() logo is not the default logo")
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown field", "sheetz: []", "field sheetz not found"},
+		{"invalid color", "rowColor: red", "#RRGGBB"},
+		{"no sheets", "sheets: []", "at least one sheet"},
+		{"unknown sheet", "sheets: [{name: Summary}]", "unknown sheet Summary"},
+		{"duplicated sheet", "sheets: [{name: Costs}, {name: Costs}]", "more than once"},
+		{"unknown column", "sheets: [{name: Costs, columns: [Price]}]", "unknown column Price"},
+		{"unknown sort column", "sheets: [{name: Costs, sort: [{column: Price}]}]", "unknown sort column Price"},
+		{"pivot before recommendations", "sheets: [{name: PivotTable}, {name: Recommendations}]", "after the Recommendations sheet"},
+		{"pivot without columns", "sheets: [{name: Recommendations, columns: [Impact]}, {name: PivotTable}]", "requires the Implemented column"},
+		{"missing logo", "logo: missing.png", "error reading excel profile logo"},
+		{"invalid logo", "logo: logo.bmp", "png, jpg or gif"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadProfile(writeProfile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadProfile() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileApply(t *testing.T) {
+	p := &Profile{Sheets: []SheetProfile{{
+		Name:    SheetCosts,
+		Columns: []string{"ServiceName", "Value"},
+		Sort:    []SortKey{{Column: "Value", Descending: true}, {Column: "ServiceName"}},
+	}}}
+	records := [][]string{
+		{"From", "To", "Subscription", "Subscription Name", "ServiceName", "Value", "Currency"},
+		{"", "", "", "", "Storage", "9.5", "EUR"},
+		{"", "", "", "", "Compute", "10", "EUR"},
+		{"", "", "", "", "Backup", "9.5", "EUR"},
+	}
+
+	want := [][]string{{"ServiceName", "Value"}, {"Compute", "10"}, {"Backup", "9.5"}, {"Storage", "9.5"}}
+	if got := p.apply(SheetCosts, records); !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %v, want %v", got, want)
+	}
+
+	// sheets without settings are returned as is
+	if got := p.apply(SheetAdvisor, records); !reflect.DeepEqual(got, records) {
+		t.Errorf("apply() = %v, want %v", got, records)
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"High", "Medium", 1},
+		{"Low", "Medium", -1},
+		{"high", "High", 0},
+		{"10", "9", 1},
+		{"b", "a", 1},
+	}
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareValues(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCreateExcelReport(t *testing.T) {
+	data := newTestData(t)
+	if err := CreateExcelReport(data, DefaultProfile()); err != nil {
+		t.Fatalf("CreateExcelReport() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(data.OutputFileName + ".xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := []string{SheetOverview, SheetRecommendations, SheetImpactedResources, SheetResourceTypes, SheetInventory, SheetAdvisor, SheetDefender, SheetCosts, SheetPivotTable}
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateExcelReport() sheets = %v, want %v", got, want)
+	}
+	if v, _ := f.GetCellValue(SheetRecommendations, "L4"); v != "Recommendation Id" {
+		t.Errorf("CreateExcelReport() last header = %s", v)
+	}
+}
+
+func TestCreateExcelReportWithProfile(t *testing.T) {
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(logo, embeded.GetTemplates("microsoft.png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadProfile(writeProfile(t, `
+logo: `+logo+`
+headerColor: "#112233"
+sheets:
+  - name: ImpactedResources
+  - name: Recommendations
+    columns: [Recommendation Id, Impact, Read More]
+    sort:
+      - column: Impact
+        descending: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := newTestData(t)
+	if err := CreateExcelReport(data, p); err != nil {
+		t.Fatalf("CreateExcelReport() error = %v", err)
+	}
+
+	f, err := excelize.OpenFile(data.OutputFileName + ".xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got, want := f.GetSheetList(), []string{SheetImpactedResources, SheetRecommendations}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateExcelReport() sheets = %v, want %v", got, want)
+	}
+
+	rows, err := f.GetRows(SheetRecommendations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Recommendation Id", "Impact", "Read More"},
+		{"st-002", "High", "https://learn.microsoft.com/st-002"},
+		{"st-001", "Low", "https://learn.microsoft.com/st-001"},
+	}
+	if got := rows[3:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateExcelReport() rows = %v, want %v", got, want)
+	}
+
+	if ok, link, _ := f.GetCellHyperLink(SheetRecommendations, "C5"); !ok || link != "https://learn.microsoft.com/st-002" {
+		t.Errorf("CreateExcelReport() link = %s", link)
+	}
+
+	style, _ := f.GetCellStyle(SheetRecommendations, "A4")
+	s, _ := f.GetStyle(style)
+	if s.Fill.Color[0] != "112233" && s.Fill.Color[0] != "#112233" {
+		t.Errorf("CreateExcelReport() header color = %v", s.Fill.Color)
+	}
+}
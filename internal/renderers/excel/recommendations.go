@@ -6,23 +6,24 @@ package excel
 import (
 	"fmt"
 	_ "image/png"
-	"strconv"
 
 	"github.com/Azure/azqr/internal/renderers"
 	"github.com/rs/zerolog/log"
 	"github.com/xuri/excelize/v2"
 )
 
-func renderRecommendations(f *excelize.File, data *renderers.ReportData) int {
-	sheetName := "Recommendations"
-	err := f.SetSheetName("Sheet1", sheetName)
+func renderRecommendations(f *excelize.File, data *renderers.ReportData, p *Profile) (int, error) {
+	sheetName := SheetRecommendations
+	_, err := f.NewSheet(sheetName)
 	if err != nil {
-		log.Fatal().Err(err).Msgf("Failed to create %s sheet", sheetName)
+		return 0, fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
 	}
-		
-	records := data.RecommendationsTable()
+
+	records := p.apply(sheetName, data.RecommendationsTable())
 	headers := records[0]
-	createFirstRow(f, sheetName, headers)
+	if err := createFirstRow(f, p, sheetName, headers); err != nil {
+		return 0, err
+	}
 
 	if len(data.Recomendations) > 0 {
 		records = records[1:]
@@ -31,33 +32,37 @@ func renderRecommendations(f *excelize.File, data *renderers.ReportData) int {
 			currentRow += 1
 			cell, err := excelize.CoordinatesToCellName(1, currentRow)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to get cell")
+				return 0, fmt.Errorf("failed to get cell: %w", err)
 			}
 			err = f.SetSheetRow(sheetName, cell, &row)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to set row")
+				return 0, fmt.Errorf("failed to set row: %w", err)
 			}
-			setHyperLink(f, sheetName, 11, currentRow)
+			setHyperLink(f, sheetName, headers, "Read More", currentRow)
 		}
 
-		configureSheet(f, sheetName, headers, currentRow)
-		return currentRow
+		return currentRow, configureSheet(f, p, sheetName, headers, currentRow)
 	} else {
 		log.Info().Msgf("Skipping %s. No data to render", sheetName)
-		return 0
+		return 0, nil
 	}
 }
 
-func renderRecommendationsPivotTables(f *excelize.File, lastRow int) {
-	sheetName := "PivotTable"
+func renderRecommendationsPivotTables(f *excelize.File, p *Profile, lastRow int) error {
+	sheetName := SheetPivotTable
 	if lastRow > 0 {
-		_, err := f.NewSheet(sheetName)
+		lastCell, err := excelize.CoordinatesToCellName(len(p.headers(SheetRecommendations)), lastRow)
 		if err != nil {
-			log.Fatal().Err(err).Msgf("Failed to create %s sheet", sheetName)
+			return fmt.Errorf("failed to get cell: %w", err)
+		}
+		dataRange := fmt.Sprintf("%s!A4:%s", SheetRecommendations, lastCell)
+
+		if _, err := f.NewSheet(sheetName); err != nil {
+			return fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
 		}
 
 		if err := f.AddPivotTable(&excelize.PivotTableOptions{
-			DataRange:       fmt.Sprintf("Recommendations!A4:L%s", strconv.Itoa(lastRow)),
+			DataRange:       dataRange,
 			PivotTableRange: "PivotTable!A4:F7",
 			Rows: []excelize.PivotTableField{
 				{Data: "Azure Service / Well-Architected"}, {Data: "Azure Service / Well-Architected Topic"}},
@@ -75,11 +80,11 @@ func renderRecommendationsPivotTables(f *excelize.File, lastRow int) {
 			ShowLastColumn: true,
 		}); err != nil {
 			log.Info().Err(err).Msgf("Failed to create %s pivot table", sheetName)
-			return
+			return nil
 		}
 
 		if err := f.AddPivotTable(&excelize.PivotTableOptions{
-			DataRange:       fmt.Sprintf("Recommendations!A4:L%s", strconv.Itoa(lastRow)),
+			DataRange:       dataRange,
 			PivotTableRange: "PivotTable!I4:N7",
 			Filter: []excelize.PivotTableField{
 				{Data: "Implemented"}},
@@ -97,9 +102,10 @@ func renderRecommendationsPivotTables(f *excelize.File, lastRow int) {
 			ShowLastColumn: true,
 		}); err != nil {
 			log.Info().Err(err).Msgf("Failed to create %s pivot table", sheetName)
-			return
+			return nil
 		}
 	} else {
 		log.Info().Msgf("Skipping %s. No data to render", sheetName)
 	}
+	return nil
 }
@@ -0,0 +1,46 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package excel
+
+import (
+	"fmt"
+	_ "image/png"
+
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/xuri/excelize/v2"
+)
+
+func renderSuppressed(f *excelize.File, data *renderers.ReportData, p *Profile) error {
+	if len(data.Suppressed) == 0 {
+		return nil
+	}
+
+	sheetName := SheetSuppressed
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create %s sheet: %w", sheetName, err)
+	}
+
+	records := p.apply(sheetName, data.SuppressedTable())
+	headers := records[0]
+	if err := createFirstRow(f, p, sheetName, headers); err != nil {
+		return err
+	}
+
+	records = records[1:]
+	currentRow := 4
+	for _, row := range records {
+		currentRow += 1
+		cell, err := excelize.CoordinatesToCellName(1, currentRow)
+		if err != nil {
+			return fmt.Errorf("failed to get cell: %w", err)
+		}
+		err = f.SetSheetRow(sheetName, cell, &row)
+		if err != nil {
+			return fmt.Errorf("failed to set row: %w", err)
+		}
+	}
+
+	return configureSheet(f, p, sheetName, headers, currentRow)
+}
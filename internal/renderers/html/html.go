@@ -0,0 +1,210 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package html
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azqr/internal/embeded"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Report - Data of the html template
+	Report struct {
+		Generated     string
+		Logo          template.URL
+		Subscriptions []string
+		Categories    []string
+		Impacts       []string
+		Sections      []Section
+	}
+
+	// Section - Table of the report
+	Section struct {
+		ID      string
+		Title   string
+		Headers []string
+		Rows    []Row
+		// Findings is set for the sections listed in the drill-down of a resource
+		Findings bool
+	}
+
+	// Row - Row of a table with the values used to filter it and to drill down to its resource
+	Row struct {
+		Cells        []Cell
+		Subscription string
+		Category     string
+		Impact       string
+		Resource     string
+	}
+
+	// Cell - Value of a row. Links are rendered as anchors and resources open the drill-down.
+	Cell struct {
+		Value    string
+		Link     bool
+		Resource bool
+	}
+
+	// columns - Header names of the columns a section is filtered by
+	columns struct {
+		subscription string
+		category     string
+		impact       string
+		resource     string
+		links        []string
+	}
+)
+
+// CreateHtmlReport - Writes a single, self-contained html file with all the tables of the report
+func CreateHtmlReport(data *renderers.ReportData) error {
+	filename := fmt.Sprintf("%s.html", data.OutputFileName)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	t, err := template.New("report").Parse(string(embeded.GetTemplates("report.html")))
+	if err != nil {
+		return fmt.Errorf("error parsing html template: %w", err)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating html: %w", err)
+	}
+	defer f.Close()
+
+	if err := t.Execute(f, NewReport(data)); err != nil {
+		return fmt.Errorf("error writing html: %w", err)
+	}
+	return nil
+}
+
+// NewReport - Builds the sections of the report from the report tables
+func NewReport(data *renderers.ReportData) Report {
+	names := subscriptionNames(data)
+
+	sections := []Section{
+		newSection("recommendations", "Recommendations", data.RecommendationsTable(), columns{category: "Resiliency Category", impact: "Impact", links: []string{"Read More"}}, names, false),
+		newSection("impacted", "Impacted Resources", data.ImpactedTable(), columns{subscription: "Subscription Name", category: "Category", impact: "Impact", resource: "Id", links: []string{"Learn"}}, names, true),
+		newSection("types", "Resource Types", data.ResourceTypesTable(), columns{subscription: "Subscription"}, names, false),
+		newSection("inventory", "Inventory", data.ResourcesTable(), columns{subscription: "Subscription ID", resource: "Resource ID"}, names, false),
+		newSection("advisor", "Advisor", data.AdvisorTable(), columns{subscription: "Subscription Name", category: "Category", impact: "Impact", resource: "ResourceID"}, names, true),
+		newSection("defender", "Defender", data.DefenderTable(), columns{subscription: "Subscription Name"}, names, false),
+		newSection("costs", "Costs", data.CostTable(), columns{subscription: "Subscription Name"}, names, false),
+	}
+
+	if len(data.Errors) > 0 {
+		sections = append(sections, newSection("errors", "Errors", data.ErrorsTable(), columns{subscription: "Subscription Name"}, names, false))
+	}
+
+	subscriptions, categories, impacts := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, s := range sections {
+		for _, r := range s.Rows {
+			subscriptions[r.Subscription] = true
+			categories[r.Category] = true
+			impacts[r.Impact] = true
+		}
+	}
+
+	return Report{
+		Generated:     time.Now().Format("2006-01-02 15:04"),
+		Logo:          template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(embeded.GetTemplates("microsoft.png"))),
+		Subscriptions: keys(subscriptions),
+		Categories:    keys(categories),
+		Impacts:       keys(impacts),
+		Sections:      sections,
+	}
+}
+
+func newSection(id, title string, records [][]string, c columns, names map[string]string, findings bool) Section {
+	headers := records[0]
+	index := func(name string) int {
+		for i, h := range headers {
+			if name != "" && h == name {
+				return i
+			}
+		}
+		return -1
+	}
+	value := func(row []string, name string) string {
+		if i := index(name); i >= 0 {
+			return row[i]
+		}
+		return ""
+	}
+
+	links := map[int]bool{}
+	for _, l := range c.links {
+		if i := index(l); i >= 0 {
+			links[i] = true
+		}
+	}
+	resource := index(c.resource)
+
+	rows := []Row{}
+	for _, record := range records[1:] {
+		row := Row{
+			Subscription: value(record, c.subscription),
+			Category:     value(record, c.category),
+			Impact:       value(record, c.impact),
+			Resource:     strings.ToLower(value(record, c.resource)),
+		}
+
+		// the inventory only has the subscription id
+		if name, ok := names[row.Subscription]; ok {
+			row.Subscription = name
+		}
+
+		for i, v := range record {
+			row.Cells = append(row.Cells, Cell{Value: v, Link: links[i] && v != "", Resource: i == resource && v != ""})
+		}
+		rows = append(rows, row)
+	}
+
+	return Section{ID: id, Title: title, Headers: headers, Rows: rows, Findings: findings}
+}
+
+// subscriptionNames returns the names of the subscriptions by their masked id, so every section is filtered by name
+func subscriptionNames(data *renderers.ReportData) map[string]string {
+	names := map[string]string{}
+	add := func(id, name string) {
+		if id != "" && name != "" {
+			names[renderers.MaskSubscriptionID(id, data.Mask)] = name
+		}
+	}
+
+	for _, r := range data.AprlData {
+		add(r.SubscriptionID, r.SubscriptionName)
+	}
+	for _, r := range data.AzqrData {
+		add(r.SubscriptionID, r.SubscriptionName)
+	}
+	for _, r := range data.DefenderData {
+		add(r.SubscriptionID, r.SubscriptionName)
+	}
+	for _, r := range data.AdvisorData {
+		add(r.SubscriptionID, r.SubscriptionName)
+	}
+	for _, r := range data.CostData.Items {
+		add(r.SubscriptionID, r.SubscriptionName)
+	}
+	return names
+}
+
+func keys(m map[string]bool) []string {
+	k := []string{}
+	for v := range m {
+		if v != "" {
+			k = append(k, v)
+		}
+	}
+	sort.Strings(k)
+	return k
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package html
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData() *renderers.ReportData {
+	data := renderers.NewReportData("", true)
+	siteID := "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Web/sites/app"
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: "Production",
+			ResourceGroup:    "rg",
+			Type:             "Microsoft.Web/sites",
+			ServiceName:      "app",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Recommendation: "App Service should use TLS 1.2", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh, NotCompliant: true, LearnMoreUrl: "https://learn.microsoft.com/tls"},
+			},
+		},
+	}
+	data.AdvisorData = []scanners.AdvisorResult{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", Name: "app", Category: "Cost", Impact: "Medium", Description: "Right size", ResourceID: strings.ToUpper(siteID)},
+	}
+	data.Resources = []*azqr.Resource{
+		{ID: siteID, SubscriptionID: subscriptionID, ResourceGroup: "rg", Type: "Microsoft.Web/sites", Name: "app"},
+	}
+	return &data
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport(newTestData())
+
+	if strings.Join(report.Subscriptions, ",") != "Production" {
+		t.Errorf("NewReport() subscriptions = %v, want Production", report.Subscriptions)
+	}
+	if strings.Join(report.Impacts, ",") != "High,Medium" {
+		t.Errorf("NewReport() impacts = %v, want High,Medium", report.Impacts)
+	}
+	if !strings.HasPrefix(string(report.Logo), "data:image/png;base64,") {
+		t.Errorf("NewReport() logo is not embedded")
+	}
+
+	sections := map[string]Section{}
+	for _, s := range report.Sections {
+		sections[s.ID] = s
+	}
+	for _, id := range []string{"recommendations", "impacted", "types", "inventory", "advisor", "defender", "costs"} {
+		if _, ok := sections[id]; !ok {
+			t.Errorf("NewReport() is missing the %s section", id)
+		}
+	}
+	if _, ok := sections["errors"]; ok {
+		t.Errorf("NewReport() rendered the errors section without errors")
+	}
+
+	// the inventory is filtered by subscription name and its resources drill down to the findings of the other sections
+	inventory := sections["inventory"].Rows[0]
+	impacted := sections["impacted"].Rows[0]
+	advisor := sections["advisor"].Rows[0]
+	if inventory.Subscription != "Production" {
+		t.Errorf("NewReport() inventory subscription = %s, want Production", inventory.Subscription)
+	}
+	if inventory.Resource == "" || inventory.Resource != impacted.Resource || inventory.Resource != advisor.Resource {
+		t.Errorf("NewReport() resources = %s, %s, %s, want the same resource", inventory.Resource, impacted.Resource, advisor.Resource)
+	}
+	if strings.Contains(inventory.Resource, subscriptionID) {
+		t.Errorf("NewReport() resource = %s, want masked subscription", inventory.Resource)
+	}
+
+	links, resources := 0, 0
+	for _, c := range impacted.Cells {
+		if c.Link {
+			links++
+		}
+		if c.Resource {
+			resources++
+		}
+	}
+	if links != 1 || resources != 1 {
+		t.Errorf("NewReport() impacted links = %d, resources = %d, want 1 and 1", links, resources)
+	}
+}
+
+func TestCreateHtmlReport(t *testing.T) {
+	data := newTestData()
+	data.OutputFileName = filepath.Join(t.TempDir(), "azqr_report")
+	data.Errors = []azqr.ScanError{{SubscriptionID: subscriptionID, SubscriptionName: "Production", Component: "Advisor", Message: "forbidden <403>"}}
+
+	if err := CreateHtmlReport(data); err != nil {
+		t.Fatalf("CreateHtmlReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(data.OutputFileName + ".html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	// the report works offline
+	if external := regexp.MustCompile(`<(script|link|img)[^>]+(src|href)="https?:`); external.MatchString(html) {
+		t.Errorf("CreateHtmlReport() references external files: %s", external.FindString(html))
+	}
+
+	for _, want := range []string{`id="errors"`, "forbidden &lt;403&gt;", `href="https://learn.microsoft.com/tls"`, `data-subscription="Production"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("CreateHtmlReport() doesn't contain %s", want)
+		}
+	}
+	if strings.Contains(html, subscriptionID) {
+		t.Errorf("CreateHtmlReport() contains the unmasked subscription id")
+	}
+}
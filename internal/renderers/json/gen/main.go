@@ -0,0 +1,27 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+// gen writes the JSON Schema of the json report to the file given as argument
+package main
+
+import (
+	"os"
+
+	"github.com/Azure/azqr/internal/renderers/json"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal().Msg("usage: gen <schema_file>")
+	}
+
+	schema, err := json.Schema()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to generate the schema")
+	}
+
+	if err := os.WriteFile(os.Args[1], schema, 0644); err != nil {
+		log.Fatal().Err(err).Msg("Failed to write the schema")
+	}
+}
@@ -3,93 +3,82 @@
 
 package json
 
+//go:generate go run ./gen ../../../docs/static/schemas/azqr-report.schema.json
+
 import (
 	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/Azure/azqr/internal/renderers"
+	"github.com/invopop/jsonschema"
 	"github.com/rs/zerolog/log"
 )
 
-func CreateJsonReport(data *renderers.ReportData) {
-	results := []interface{}{}
+// SchemaID - Id of the JSON Schema of the json report
+const SchemaID = "https://azure.github.io/azqr/schemas/azqr-report.schema.json"
+
+// CreateJsonReport - Writes every section of the report to <OutputFileName>.json
+func CreateJsonReport(data *renderers.ReportData) error {
+	return writeData(NewReport(data), data.OutputFileName, "json")
+}
+
+// Schema - Returns the JSON Schema of the json report, generated from the Report type
+func Schema() ([]byte, error) {
+	r := jsonschema.Reflector{}
+	s := r.Reflect(&Report{})
+	s.ID = SchemaID
+	s.Title = "Azure Quick Review report"
+	s.Description = fmt.Sprintf("Version %d of the azqr json report", SchemaVersion)
+
+	js, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling schema: %w", err)
+	}
+	return append(js, '\n'), nil
+}
 
-	resources := renderers.ResourceResults{
-		Resource: getResources(data),
+func CreateChangesReport(data *renderers.ReportData) error {
+	changes := renderers.ChangesData{
+		Findings:  []renderers.FindingChange{},
+		Resources: []renderers.ResourceChange{},
 	}
-	results = append(results, resources)
 
-	types := renderers.ResourceTypeCountResults{
-		ResourceType: data.ResourceTypeCount,
+	if data.Changes != nil {
+		for _, f := range data.Changes.Findings {
+			f.SubscriptionID = renderers.MaskSubscriptionID(f.SubscriptionID, data.Mask)
+			f.ResourceID = renderers.MaskSubscriptionIDInResourceID(f.ResourceID, data.Mask)
+			changes.Findings = append(changes.Findings, f)
+		}
+
+		for _, r := range data.Changes.Resources {
+			r.SubscriptionID = renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask)
+			r.ResourceID = renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask)
+			changes.Resources = append(changes.Resources, r)
+		}
 	}
-	results = append(results, types)
 
-	writeData(results, data.OutputFileName, "json")
+	return writeData(changes, data.OutputFileName, "changes.json")
 }
 
-func writeData(data []interface{}, fileName, extension string) {
+func writeData(data interface{}, fileName, extension string) error {
 	filename := fmt.Sprintf("%s.%s", fileName, extension)
 	log.Info().Msgf("Generating Report: %s", filename)
 
 	f, err := os.Create(filename)
 	if err != nil {
-		log.Fatal().Err(err).Msg("error creating json:")
+		return fmt.Errorf("error creating json: %w", err)
 	}
 	defer f.Close()
 
 	js, err := json.MarshalIndent(data, "", "\t")
 	if err != nil {
-		log.Fatal().Err(err).Msg("error marshaling data:")
+		return fmt.Errorf("error marshaling data: %w", err)
 	}
 
 	_, err = f.Write(js)
 	if err != nil {
-		log.Fatal().Err(err).Msg("error writing json:")
-	}
-}
-
-func getResources(data *renderers.ReportData) []renderers.ResourceResult {
-	rows := []renderers.ResourceResult{}
-
-	for _, r := range data.AprlData {
-		row := renderers.ResourceResult{
-			ValidationAction: "Azure Resource Graph",
-			RecommendationId: r.RecommendationID,
-			Name:             r.Name,
-			Id:               r.ResourceID,
-			Param1:           r.Param1,
-			Param2:           r.Param2,
-			Param3:           r.Param3,
-			Param4:           r.Param4,
-			Param5:           r.Param5,
-			CheckName:        "",
-			Selector:         r.Source,
-		}
-		rows = append(rows, row)
+		return fmt.Errorf("error writing json: %w", err)
 	}
-
-	// Not sure if we can upload AZQR results
-	// for _, d := range data.AzqrData {
-	// 	for _, r := range d.Recommendations {
-	// 		if r.NotCompliant {
-	// 			row := renderers.ResourceResult{
-	// 				ValidationAction: "Azure Resource Manager",
-	// 				RecommendationId: r.RecommendationID,
-	// 				Name:             d.ServiceName,
-	// 				Id:               renderers.MaskSubscriptionIDInResourceID(d.ResourceID(), data.Mask),
-	// 				Param1:           r.Result,
-	// 				Param2:           "",
-	// 				Param3:           "",
-	// 				Param4:           "",
-	// 				Param5:           "",
-	// 				CheckName:        "",
-	// 				Selector:         "AZQR",
-	// 			}
-	// 			rows = append(rows, row)
-	// 		}
-	// 	}
-	// }
-	
-	return rows
+	return nil
 }
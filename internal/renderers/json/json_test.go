@@ -0,0 +1,165 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData() *renderers.ReportData {
+	data := renderers.NewReportData("", true)
+	siteID := "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Web/sites/app"
+	data.Recomendations = map[string]map[string]azqr.AprlRecommendation{
+		"microsoft.web/sites": {
+			"app-001": {RecommendationID: "app-001", ResourceType: "Microsoft.Web/sites", Recommendation: "App Service should use TLS 1.2", Category: "Security", Impact: "High"},
+			"app-002": {RecommendationID: "app-002", ResourceType: "Microsoft.Web/sites", Recommendation: "App Service should have diagnostic settings", Category: "Monitoring and Alerting", Impact: "Low"},
+		},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: "Production",
+			ResourceGroup:    "rg",
+			Type:             "Microsoft.Web/sites",
+			ServiceName:      "app",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Recommendation: "App Service should use TLS 1.2", Category: azqr.CategorySecurity, Impact: azqr.ImpactHigh, NotCompliant: true, Result: "1.0"},
+				"app-002": {RecommendationID: "app-002", Recommendation: "App Service should have diagnostic settings", Category: azqr.CategoryMonitoringAndAlerting, Impact: azqr.ImpactLow},
+			},
+		},
+	}
+	data.AprlData = []azqr.AprlResult{
+		{RecommendationID: "a1b2c3d4-0000-0000-0000-000000000000", ResourceType: "Microsoft.Web/sites", Category: azqr.CategoryHighAvailability, Impact: azqr.ImpactMedium, SubscriptionID: subscriptionID, SubscriptionName: "Production", ResourceGroup: "rg", Name: "app", ResourceID: siteID, Source: "APRL", Param1: "zones"},
+	}
+	data.Resources = []*azqr.Resource{
+		{ID: siteID, SubscriptionID: subscriptionID, ResourceGroup: "rg", Location: "westeurope", Type: "Microsoft.Web/sites", Name: "app"},
+	}
+	data.ResourceTypeCount = []azqr.ResourceTypeCount{
+		{Subscription: "Production", ResourceType: "Microsoft.Web/sites", Count: 1, AvailableInAPRL: "Yes"},
+	}
+	data.AdvisorData = []scanners.AdvisorResult{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", Name: "app", Category: "Cost", Impact: "Medium", ResourceID: siteID},
+	}
+	data.DefenderData = []scanners.DefenderResult{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", Name: "AppServices", Tier: "Free"},
+	}
+	data.CostData.Items = []*scanners.CostResultItem{
+		{SubscriptionID: subscriptionID, SubscriptionName: "Production", ServiceName: "Azure App Service", Value: "12.5", Currency: "EUR"},
+	}
+	data.Errors = []azqr.ScanError{{SubscriptionID: subscriptionID, SubscriptionName: "Production", Component: "Advisor", Message: "forbidden"}}
+	return &data
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport(newTestData())
+
+	if report.Kind != Kind || report.SchemaVersion != SchemaVersion {
+		t.Errorf("NewReport() kind = %s, schemaVersion = %d", report.Kind, report.SchemaVersion)
+	}
+
+	if len(report.Recommendations) != 2 {
+		t.Fatalf("NewReport() recommendations = %d, want 2", len(report.Recommendations))
+	}
+	if r := report.Recommendations[0]; r.RecommendationID != "app-001" || r.Source != "AZQR" || r.Implemented || r.ImpactedResources != 1 {
+		t.Errorf("NewReport() recommendation = %+v, want app-001 not implemented", r)
+	}
+	if r := report.Recommendations[1]; !r.Implemented || r.ImpactedResources != 0 {
+		t.Errorf("NewReport() recommendation = %+v, want app-002 implemented", r)
+	}
+
+	// APRL findings and the non-compliant AZQR recommendations
+	if len(report.Impacted) != 2 {
+		t.Fatalf("NewReport() impacted = %d, want 2", len(report.Impacted))
+	}
+	if i := report.Impacted[0]; i.Source != "APRL" || i.Param1 != "zones" || i.ValidatedUsing != "Azure Resource Graph" {
+		t.Errorf("NewReport() impacted = %+v, want the APRL finding", i)
+	}
+	if i := report.Impacted[1]; i.RecommendationID != "app-001" || i.Result != "1.0" || i.ValidatedUsing != "Azure Resource Manager" {
+		t.Errorf("NewReport() impacted = %+v, want app-001", i)
+	}
+
+	if rt := report.ResourceTypes[0]; rt.Count != 1 || !rt.AvailableInAprl {
+		t.Errorf("NewReport() resource type = %+v, want 1 available in APRL", rt)
+	}
+	if report.Costs.Items[0].Value != 12.5 {
+		t.Errorf("NewReport() cost = %v, want 12.5", report.Costs.Items[0].Value)
+	}
+	if len(report.Inventory) != 1 || len(report.Advisor) != 1 || len(report.Defender) != 1 || len(report.Errors) != 1 {
+		t.Errorf("NewReport() inventory = %d, advisor = %d, defender = %d, errors = %d, want 1 of each",
+			len(report.Inventory), len(report.Advisor), len(report.Defender), len(report.Errors))
+	}
+
+	js, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(js), subscriptionID) {
+		t.Errorf("NewReport() contains the unmasked subscription id")
+	}
+}
+
+func TestNewReport_Unmasked(t *testing.T) {
+	data := newTestData()
+	data.Mask = false
+	report := NewReport(data)
+
+	js, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the inventory, the impacted resources, advisor, defender, costs and errors
+	if got := strings.Count(string(js), subscriptionID); got < 10 {
+		t.Errorf("NewReport() contains the subscription id %d times, want unmasked ids in every section", got)
+	}
+}
+
+func TestCreateJsonReport(t *testing.T) {
+	data := newTestData()
+	data.OutputFileName = filepath.Join(t.TempDir(), "azqr_report")
+
+	if err := CreateJsonReport(data); err != nil {
+		t.Fatalf("CreateJsonReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(data.OutputFileName + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := map[string]interface{}{}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"kind", "schemaVersion", "generatedAt", "recommendations", "impacted", "resourceTypes", "inventory", "advisor", "defender", "costs", "errors"} {
+		if _, ok := report[key]; !ok {
+			t.Errorf("CreateJsonReport() is missing %s", key)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	committed, err := os.ReadFile("../../../docs/static/schemas/azqr-report.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema, committed) {
+		t.Errorf("docs/static/schemas/azqr-report.schema.json is stale, run go generate ./internal/renderers/json")
+	}
+}
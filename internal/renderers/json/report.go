@@ -0,0 +1,313 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package json
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Kind - Identifies a file as an azqr json report
+	Kind = "azqr.report"
+	// SchemaVersion - Current version of the json report format. It changes when a field is removed or changes its type.
+	// Version 1 is the array of resources, resource types and errors written by earlier releases.
+	SchemaVersion = 2
+)
+
+type (
+	// Report - Complete result of a scan. Subscription ids are masked unless --mask=false is used.
+	Report struct {
+		Kind            string           `json:"kind" jsonschema:"const=azqr.report"`
+		SchemaVersion   int              `json:"schemaVersion" jsonschema:"const=2"`
+		GeneratedAt     time.Time        `json:"generatedAt"`
+		Recommendations []Recommendation `json:"recommendations"`
+		Impacted        []Impacted       `json:"impacted"`
+		ResourceTypes   []ResourceType   `json:"resourceTypes"`
+		Inventory       []Resource       `json:"inventory"`
+		Advisor         []Advisor        `json:"advisor"`
+		Defender        []Defender       `json:"defender"`
+		Costs           Costs            `json:"costs"`
+		Errors          []azqr.ScanError `json:"errors"`
+		// Suppressed are the findings moved out of the report by the suppressions of the filters
+		Suppressed []azqr.SuppressedResult `json:"suppressed"`
+	}
+
+	// Recommendation - AZQR, APRL or custom recommendation evaluated by the scan
+	Recommendation struct {
+		RecommendationID  string `json:"recommendationId"`
+		Source            string `json:"source" jsonschema:"enum=AZQR,enum=APRL,enum=CUSTOM"`
+		ResourceType      string `json:"resourceType"`
+		Category          string `json:"category"`
+		Impact            string `json:"impact"`
+		Recommendation    string `json:"recommendation"`
+		Description       string `json:"description"`
+		LearnMoreURL      string `json:"learnMoreUrl"`
+		Implemented       bool   `json:"implemented"`
+		ImpactedResources int    `json:"impactedResources"`
+	}
+
+	// Impacted - Resource that doesn't comply with a recommendation
+	Impacted struct {
+		ValidatedUsing   string `json:"validatedUsing" jsonschema:"enum=Azure Resource Graph,enum=Azure Resource Manager"`
+		Source           string `json:"source" jsonschema:"enum=AZQR,enum=APRL,enum=CUSTOM"`
+		RecommendationID string `json:"recommendationId"`
+		Recommendation   string `json:"recommendation"`
+		Category         string `json:"category"`
+		Impact           string `json:"impact"`
+		ResourceType     string `json:"resourceType"`
+		SubscriptionID   string `json:"subscriptionId"`
+		SubscriptionName string `json:"subscriptionName"`
+		ManagementGroup  string `json:"managementGroup,omitempty"`
+		ResourceGroup    string `json:"resourceGroup"`
+		Name             string `json:"name"`
+		ID               string `json:"id"`
+		Result           string `json:"result,omitempty"`
+		Param1           string `json:"param1,omitempty"`
+		Param2           string `json:"param2,omitempty"`
+		Param3           string `json:"param3,omitempty"`
+		Param4           string `json:"param4,omitempty"`
+		Param5           string `json:"param5,omitempty"`
+		LearnMoreURL     string `json:"learnMoreUrl"`
+	}
+
+	// ResourceType - Number of resources of a type in a subscription
+	ResourceType struct {
+		Subscription    string `json:"subscription"`
+		ResourceType    string `json:"resourceType"`
+		Count           int    `json:"count"`
+		AvailableInAprl bool   `json:"availableInAprl"`
+	}
+
+	// Resource - Resource of the inventory
+	Resource struct {
+		SubscriptionID  string `json:"subscriptionId"`
+		ManagementGroup string `json:"managementGroup,omitempty"`
+		ResourceGroup   string `json:"resourceGroup"`
+		Location        string `json:"location"`
+		Type            string `json:"type"`
+		Name            string `json:"name"`
+		SkuName         string `json:"skuName"`
+		SkuTier         string `json:"skuTier"`
+		Kind            string `json:"kind"`
+		SLA             string `json:"sla"`
+		ID              string `json:"id"`
+	}
+
+	// Advisor - Azure Advisor recommendation
+	Advisor struct {
+		SubscriptionID   string `json:"subscriptionId"`
+		SubscriptionName string `json:"subscriptionName"`
+		Type             string `json:"type"`
+		Name             string `json:"name"`
+		Category         string `json:"category"`
+		Impact           string `json:"impact"`
+		Description      string `json:"description"`
+		ResourceID       string `json:"resourceId"`
+		RecommendationID string `json:"recommendationId"`
+	}
+
+	// Defender - Microsoft Defender for Cloud plan of a subscription
+	Defender struct {
+		SubscriptionID   string `json:"subscriptionId"`
+		SubscriptionName string `json:"subscriptionName"`
+		Name             string `json:"name"`
+		Tier             string `json:"tier"`
+		Deprecated       bool   `json:"deprecated"`
+	}
+
+	// Costs - Costs of the period scanned
+	Costs struct {
+		From  time.Time `json:"from"`
+		To    time.Time `json:"to"`
+		Items []Cost    `json:"items"`
+	}
+
+	// Cost - Cost of a service in a subscription
+	Cost struct {
+		SubscriptionID   string  `json:"subscriptionId"`
+		SubscriptionName string  `json:"subscriptionName"`
+		ServiceName      string  `json:"serviceName"`
+		Value            float64 `json:"value"`
+		Currency         string  `json:"currency"`
+	}
+)
+
+// NewReport - Converts the report data to the json report, masking the subscription ids if requested
+func NewReport(data *renderers.ReportData) Report {
+	report := Report{
+		Kind:            Kind,
+		SchemaVersion:   SchemaVersion,
+		GeneratedAt:     time.Now().UTC(),
+		Recommendations: []Recommendation{},
+		Impacted:        []Impacted{},
+		ResourceTypes:   []ResourceType{},
+		Inventory:       []Resource{},
+		Advisor:         []Advisor{},
+		Defender:        []Defender{},
+		Costs:           Costs{From: data.CostData.From, To: data.CostData.To, Items: []Cost{}},
+		Errors:          data.MaskedErrors(),
+		Suppressed:      []azqr.SuppressedResult{},
+	}
+
+	counter := data.ImpactedCount()
+	for _, rt := range data.Recomendations {
+		for _, r := range rt {
+			learn := ""
+			if len(r.LearnMoreLink) > 0 {
+				learn = r.LearnMoreLink[0].Url
+			}
+			report.Recommendations = append(report.Recommendations, Recommendation{
+				RecommendationID:  r.RecommendationID,
+				Source:            renderers.RecommendationSource(r),
+				ResourceType:      r.ResourceType,
+				Category:          r.Category,
+				Impact:            r.Impact,
+				Recommendation:    r.Recommendation,
+				Description:       r.LongDescription,
+				LearnMoreURL:      learn,
+				Implemented:       counter[r.RecommendationID] == 0,
+				ImpactedResources: counter[r.RecommendationID],
+			})
+		}
+	}
+	sort.Slice(report.Recommendations, func(i, j int) bool {
+		return report.Recommendations[i].RecommendationID < report.Recommendations[j].RecommendationID
+	})
+
+	for _, r := range data.AprlData {
+		report.Impacted = append(report.Impacted, Impacted{
+			ValidatedUsing:   "Azure Resource Graph",
+			Source:           r.Source,
+			RecommendationID: r.RecommendationID,
+			Recommendation:   r.Recommendation,
+			Category:         string(r.Category),
+			Impact:           string(r.Impact),
+			ResourceType:     r.ResourceType,
+			SubscriptionID:   renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask),
+			SubscriptionName: r.SubscriptionName,
+			ManagementGroup:  data.ManagementGroup(r.SubscriptionID),
+			ResourceGroup:    r.ResourceGroup,
+			Name:             r.Name,
+			ID:               renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask),
+			Param1:           r.Param1,
+			Param2:           r.Param2,
+			Param3:           r.Param3,
+			Param4:           r.Param4,
+			Param5:           r.Param5,
+			LearnMoreURL:     r.Learn,
+		})
+	}
+
+	for _, d := range data.AzqrData {
+		ids := make([]string, 0, len(d.Recommendations))
+		for id := range d.Recommendations {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			r := d.Recommendations[id]
+			if !r.NotCompliant {
+				continue
+			}
+			report.Impacted = append(report.Impacted, Impacted{
+				ValidatedUsing:   "Azure Resource Manager",
+				Source:           "AZQR",
+				RecommendationID: r.RecommendationID,
+				Recommendation:   r.Recommendation,
+				Category:         string(r.Category),
+				Impact:           string(r.Impact),
+				ResourceType:     d.Type,
+				SubscriptionID:   renderers.MaskSubscriptionID(d.SubscriptionID, data.Mask),
+				SubscriptionName: d.SubscriptionName,
+				ManagementGroup:  data.ManagementGroup(d.SubscriptionID),
+				ResourceGroup:    d.ResourceGroup,
+				Name:             d.ServiceName,
+				ID:               renderers.MaskSubscriptionIDInResourceID(d.ResourceID(), data.Mask),
+				Result:           r.Result,
+				LearnMoreURL:     r.LearnMoreUrl,
+			})
+		}
+	}
+
+	for _, r := range data.ResourceTypeCount {
+		report.ResourceTypes = append(report.ResourceTypes, ResourceType{
+			Subscription:    r.Subscription,
+			ResourceType:    r.ResourceType,
+			Count:           int(r.Count),
+			AvailableInAprl: strings.EqualFold(r.AvailableInAPRL, "Yes"),
+		})
+	}
+
+	// the inventory table resolves the SLA of each resource
+	for i, row := range data.ResourcesTable()[1:] {
+		r := data.Resources[i]
+		report.Inventory = append(report.Inventory, Resource{
+			SubscriptionID:  renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask),
+			ManagementGroup: data.ManagementGroup(r.SubscriptionID),
+			ResourceGroup:   r.ResourceGroup,
+			Location:        r.Location,
+			Type:            r.Type,
+			Name:            r.Name,
+			SkuName:         r.SkuName,
+			SkuTier:         r.SkuTier,
+			Kind:            r.Kind,
+			SLA:             row[9],
+			ID:              renderers.MaskSubscriptionIDInResourceID(r.ID, data.Mask),
+		})
+	}
+
+	for _, r := range data.AdvisorData {
+		report.Advisor = append(report.Advisor, Advisor{
+			SubscriptionID:   renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask),
+			SubscriptionName: r.SubscriptionName,
+			Type:             r.Type,
+			Name:             r.Name,
+			Category:         r.Category,
+			Impact:           r.Impact,
+			Description:      r.Description,
+			ResourceID:       renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask),
+			RecommendationID: r.RecommendationID,
+		})
+	}
+
+	for _, r := range data.DefenderData {
+		report.Defender = append(report.Defender, Defender{
+			SubscriptionID:   renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask),
+			SubscriptionName: r.SubscriptionName,
+			Name:             r.Name,
+			Tier:             r.Tier,
+			Deprecated:       r.Deprecated,
+		})
+	}
+
+	for _, r := range data.CostData.Items {
+		value, err := strconv.ParseFloat(r.Value, 64)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Cost of %s is not a number", r.ServiceName)
+		}
+		report.Costs.Items = append(report.Costs.Items, Cost{
+			SubscriptionID:   renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask),
+			SubscriptionName: r.SubscriptionName,
+			ServiceName:      r.ServiceName,
+			Value:            value,
+			Currency:         r.Currency,
+		})
+	}
+
+	for _, r := range data.Suppressed {
+		r.SubscriptionID = renderers.MaskSubscriptionID(r.SubscriptionID, data.Mask)
+		r.ResourceID = renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask)
+		report.Suppressed = append(report.Suppressed, r)
+	}
+
+	return report
+}
@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// TestSuites - JUnit report with a test suite per resource type
+	TestSuites struct {
+		XMLName  xml.Name    `xml:"testsuites"`
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Suites   []TestSuite `xml:"testsuite"`
+	}
+
+	// TestSuite - Recommendations evaluated for the resources of a type
+	TestSuite struct {
+		Name      string     `xml:"name,attr"`
+		Tests     int        `xml:"tests,attr"`
+		Failures  int        `xml:"failures,attr"`
+		TestCases []TestCase `xml:"testcase"`
+	}
+
+	// TestCase - Recommendation evaluated for a resource. The test case fails if the resource is not compliant.
+	TestCase struct {
+		Name      string   `xml:"name,attr"`
+		ClassName string   `xml:"classname,attr"`
+		Failure   *Failure `xml:"failure,omitempty"`
+	}
+
+	// Failure - Result of a non-compliant recommendation. The type is the impact of the recommendation.
+	Failure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// CreateJUnitReport - Writes the evaluated recommendations to <OutputFileName>.junit.xml
+func CreateJUnitReport(data *renderers.ReportData) error {
+	filename := fmt.Sprintf("%s.junit.xml", data.OutputFileName)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	x, err := xml.MarshalIndent(NewTestSuites(data), "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling junit: %w", err)
+	}
+
+	if err := os.WriteFile(filename, append([]byte(xml.Header), x...), 0644); err != nil {
+		return fmt.Errorf("error writing junit: %w", err)
+	}
+	return nil
+}
+
+// NewTestSuites - Converts the AZQR recommendations evaluated for each resource to test cases, which fail when the
+// resource is not compliant. APRL results only list the non-compliant resources, so they are always failures.
+func NewTestSuites(data *renderers.ReportData) TestSuites {
+	suites := map[string]*TestSuite{}
+	suite := func(resourceType string) *TestSuite {
+		t := strings.ToLower(resourceType)
+		if _, ok := suites[t]; !ok {
+			suites[t] = &TestSuite{Name: resourceType, TestCases: []TestCase{}}
+		}
+		return suites[t]
+	}
+
+	for _, d := range data.AzqrData {
+		s := suite(d.Type)
+		resourceID := renderers.MaskSubscriptionIDInResourceID(d.ResourceID(), data.Mask)
+		for _, r := range d.Recommendations {
+			if r.RecommendationType != azqr.TypeRecommendation {
+				continue
+			}
+
+			tc := TestCase{Name: testCaseName(r.RecommendationID, r.Recommendation), ClassName: resourceID}
+			if r.NotCompliant {
+				result := r.Result
+				if result == "" {
+					result = r.Recommendation
+				}
+				tc.Failure = newFailure(result, r.Impact, r.LearnMoreUrl)
+			}
+			s.TestCases = append(s.TestCases, tc)
+		}
+	}
+
+	for _, r := range data.AprlData {
+		s := suite(r.ResourceType)
+		s.TestCases = append(s.TestCases, TestCase{
+			Name:      testCaseName(r.RecommendationID, r.Recommendation),
+			ClassName: renderers.MaskSubscriptionIDInResourceID(r.ResourceID, data.Mask),
+			Failure:   newFailure(r.Recommendation, r.Impact, r.Learn),
+		})
+	}
+
+	report := TestSuites{Name: "azqr", Suites: []TestSuite{}}
+	for _, s := range suites {
+		sort.Slice(s.TestCases, func(i, j int) bool {
+			if s.TestCases[i].ClassName != s.TestCases[j].ClassName {
+				return s.TestCases[i].ClassName < s.TestCases[j].ClassName
+			}
+			return s.TestCases[i].Name < s.TestCases[j].Name
+		})
+
+		s.Tests = len(s.TestCases)
+		for _, tc := range s.TestCases {
+			if tc.Failure != nil {
+				s.Failures++
+			}
+		}
+
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Suites = append(report.Suites, *s)
+	}
+
+	sort.Slice(report.Suites, func(i, j int) bool {
+		return strings.ToLower(report.Suites[i].Name) < strings.ToLower(report.Suites[j].Name)
+	})
+
+	return report
+}
+
+func testCaseName(id, recommendation string) string {
+	return fmt.Sprintf("%s: %s", id, recommendation)
+}
+
+func newFailure(result string, impact azqr.RecommendationImpact, learn string) *Failure {
+	text := result
+	if learn != "" {
+		text = fmt.Sprintf("%s\nLearn more: %s", result, learn)
+	}
+	return &Failure{Message: result, Type: string(impact), Text: text}
+}
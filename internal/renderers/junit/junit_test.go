@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData(mask bool) *renderers.ReportData {
+	data := renderers.NewReportData("", mask)
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID: subscriptionID,
+			ResourceGroup:  "rg",
+			Type:           "Microsoft.Web/sites",
+			ServiceName:    "app",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Recommendation: "App Service should use TLS 1.2", Impact: azqr.ImpactHigh, NotCompliant: true, Result: "TLS 1.0", LearnMoreUrl: "https://learn.microsoft.com/tls"},
+				"app-002": {RecommendationID: "app-002", Recommendation: "App Service should use HTTPS only", Impact: azqr.ImpactLow},
+				"app-003": {RecommendationID: "app-003", Recommendation: "SLA", RecommendationType: azqr.TypeSLA, Result: "99.95%"},
+			},
+		},
+	}
+	data.AprlData = []azqr.AprlResult{
+		{
+			RecommendationID: "11111111-1111-1111-1111-111111111111",
+			Recommendation:   "Use zone redundant storage",
+			ResourceType:     "Microsoft.Storage/storageAccounts",
+			Impact:           azqr.ImpactMedium,
+			ResourceID:       "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/data",
+			Learn:            "https://learn.microsoft.com/zrs",
+		},
+		{
+			RecommendationID: "22222222-2222-2222-2222-222222222222",
+			Recommendation:   "Enable zone redundancy",
+			ResourceType:     "microsoft.web/sites",
+			Impact:           azqr.ImpactLow,
+			ResourceID:       "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Web/sites/app",
+		},
+	}
+	return &data
+}
+
+func TestNewTestSuites(t *testing.T) {
+	got := NewTestSuites(newTestData(true))
+
+	if got.Tests != 4 || got.Failures != 3 {
+		t.Errorf("NewTestSuites() tests = %d, failures = %d, want 4 and 3", got.Tests, got.Failures)
+	}
+
+	// one suite per resource type, regardless of the case
+	if len(got.Suites) != 2 || got.Suites[0].Name != "Microsoft.Storage/storageAccounts" || got.Suites[1].Name != "Microsoft.Web/sites" {
+		t.Fatalf("NewTestSuites() suites = %+v", got.Suites)
+	}
+
+	sites := got.Suites[1]
+	if sites.Tests != 3 || sites.Failures != 2 {
+		t.Errorf("NewTestSuites() sites tests = %d, failures = %d, want 3 and 2", sites.Tests, sites.Failures)
+	}
+
+	for _, tc := range sites.TestCases {
+		if !strings.HasPrefix(tc.ClassName, "/subscriptions/xxxxxxxx-") {
+			t.Errorf("NewTestSuites() class name = %s, want masked resource id", tc.ClassName)
+		}
+
+		switch {
+		case strings.HasPrefix(tc.Name, "app-001"):
+			if tc.Failure == nil || tc.Failure.Message != "TLS 1.0" || tc.Failure.Type != "High" || !strings.Contains(tc.Failure.Text, "https://learn.microsoft.com/tls") {
+				t.Errorf("NewTestSuites() app-001 failure = %+v", tc.Failure)
+			}
+		case strings.HasPrefix(tc.Name, "app-002"):
+			if tc.Failure != nil {
+				t.Errorf("NewTestSuites() app-002 failed for a compliant resource")
+			}
+		case strings.HasPrefix(tc.Name, "22222222"):
+			if tc.Failure == nil || tc.Failure.Type != "Low" {
+				t.Errorf("NewTestSuites() APRL failure = %+v", tc.Failure)
+			}
+		default:
+			t.Errorf("NewTestSuites() unexpected test case %s", tc.Name)
+		}
+	}
+}
+
+func TestCreateJUnitReport(t *testing.T) {
+	data := newTestData(false)
+	data.OutputFileName = filepath.Join(t.TempDir(), "azqr_report")
+
+	if err := CreateJUnitReport(data); err != nil {
+		t.Fatalf("CreateJUnitReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(data.OutputFileName + ".junit.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := TestSuites{}
+	if err := xml.Unmarshal(content, &got); err != nil {
+		t.Fatalf("CreateJUnitReport() wrote invalid xml: %v", err)
+	}
+	if got.Failures != 3 || !strings.Contains(got.Suites[0].TestCases[0].ClassName, subscriptionID) {
+		t.Errorf("CreateJUnitReport() = %s", content)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package markdown
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/embeded"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+// topN - Number of entries of the top lists of the summary
+const topN = 10
+
+type (
+	// Summary - Aggregates of the report data available to the markdown template
+	Summary struct {
+		Generated        time.Time
+		Findings         int
+		Resources        int
+		ByImpact         []Count
+		ByCategory       []Count
+		TopResourceTypes []Count
+		TopSubscriptions []SubscriptionCount
+		FreeDefender     []DefenderPlan
+		CostFrom         time.Time
+		CostTo           time.Time
+		TopCosts         []Cost
+	}
+
+	// Count - Number of findings of an impact, category or resource type
+	Count struct {
+		Name  string
+		Count int
+	}
+
+	// SubscriptionCount - Number of findings of a subscription. The id is masked unless --mask=false is used.
+	SubscriptionCount struct {
+		ID    string
+		Name  string
+		Count int
+	}
+
+	// DefenderPlan - Defender plan on the Free tier
+	DefenderPlan struct {
+		SubscriptionID   string
+		SubscriptionName string
+		Plan             string
+	}
+
+	// Cost - Cost of a service across all subscriptions
+	Cost struct {
+		Service  string
+		Value    float64
+		Currency string
+	}
+)
+
+// CreateMarkdownReport - Writes the executive summary to <OutputFileName>.md.
+// The summary is rendered with the given template file or, if empty, with the default template.
+func CreateMarkdownReport(data *renderers.ReportData, templateFile string) error {
+	filename := fmt.Sprintf("%s.md", data.OutputFileName)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	t, err := LoadTemplate(templateFile)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating markdown: %w", err)
+	}
+	defer f.Close()
+
+	if err := t.Execute(f, NewSummary(data)); err != nil {
+		return fmt.Errorf("error writing markdown: %w", err)
+	}
+	return nil
+}
+
+// LoadTemplate - Parses the given template file or, if empty, the default template
+func LoadTemplate(templateFile string) (*template.Template, error) {
+	content := embeded.GetTemplates("summary.md")
+	if templateFile != "" {
+		var err error
+		content, err = os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading markdown template: %w", err)
+		}
+	}
+
+	t, err := template.New("summary").Funcs(template.FuncMap{"cell": cell}).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing markdown template: %w", err)
+	}
+	return t, nil
+}
+
+// NewSummary - Computes the aggregates of the summary. Findings are the APRL results and the non-compliant AZQR recommendations.
+func NewSummary(data *renderers.ReportData) Summary {
+	impacts := map[string]int{}
+	categories := map[string]int{}
+	types := map[string]int{}
+	typeNames := map[string]string{}
+	subscriptions := map[string]*SubscriptionCount{}
+	findings := 0
+
+	add := func(impact azqr.RecommendationImpact, category azqr.RecommendationCategory, resourceType, subscriptionID, subscriptionName string) {
+		findings++
+		impacts[string(impact)]++
+		categories[string(category)]++
+
+		t := strings.ToLower(resourceType)
+		types[t]++
+		if _, ok := typeNames[t]; !ok {
+			typeNames[t] = resourceType
+		}
+
+		id := renderers.MaskSubscriptionID(subscriptionID, data.Mask)
+		if _, ok := subscriptions[id]; !ok {
+			subscriptions[id] = &SubscriptionCount{ID: id, Name: subscriptionName}
+		}
+		subscriptions[id].Count++
+	}
+
+	for _, f := range data.Findings() {
+		add(f.Impact, f.Category, f.ResourceType, f.SubscriptionID, f.SubscriptionName)
+	}
+
+	summary := Summary{
+		Generated:        time.Now(),
+		Findings:         findings,
+		Resources:        len(data.Resources),
+		ByImpact:         []Count{},
+		ByCategory:       top(categories, nil, len(categories)),
+		TopResourceTypes: top(types, typeNames, topN),
+		TopSubscriptions: []SubscriptionCount{},
+		FreeDefender:     []DefenderPlan{},
+		CostFrom:         data.CostData.From,
+		CostTo:           data.CostData.To,
+		TopCosts:         []Cost{},
+	}
+
+	for _, i := range []azqr.RecommendationImpact{azqr.ImpactHigh, azqr.ImpactMedium, azqr.ImpactLow} {
+		summary.ByImpact = append(summary.ByImpact, Count{Name: string(i), Count: impacts[string(i)]})
+	}
+
+	for _, s := range subscriptions {
+		summary.TopSubscriptions = append(summary.TopSubscriptions, *s)
+	}
+	sort.Slice(summary.TopSubscriptions, func(i, j int) bool {
+		a, b := summary.TopSubscriptions[i], summary.TopSubscriptions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	if len(summary.TopSubscriptions) > topN {
+		summary.TopSubscriptions = summary.TopSubscriptions[:topN]
+	}
+
+	for _, d := range data.DefenderData {
+		if strings.EqualFold(d.Tier, "Free") && !d.Deprecated {
+			summary.FreeDefender = append(summary.FreeDefender, DefenderPlan{
+				SubscriptionID:   renderers.MaskSubscriptionID(d.SubscriptionID, data.Mask),
+				SubscriptionName: d.SubscriptionName,
+				Plan:             d.Name,
+			})
+		}
+	}
+	sort.Slice(summary.FreeDefender, func(i, j int) bool {
+		a, b := summary.FreeDefender[i], summary.FreeDefender[j]
+		if a.SubscriptionName != b.SubscriptionName {
+			return a.SubscriptionName < b.SubscriptionName
+		}
+		return a.Plan < b.Plan
+	})
+
+	costs := map[string]*Cost{}
+	for _, c := range data.CostData.Items {
+		value, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping cost of %s", c.ServiceName)
+			continue
+		}
+
+		key := c.ServiceName + "|" + c.Currency
+		if _, ok := costs[key]; !ok {
+			costs[key] = &Cost{Service: c.ServiceName, Currency: c.Currency}
+		}
+		costs[key].Value += value
+	}
+	for _, c := range costs {
+		summary.TopCosts = append(summary.TopCosts, *c)
+	}
+	sort.Slice(summary.TopCosts, func(i, j int) bool {
+		a, b := summary.TopCosts[i], summary.TopCosts[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.Service < b.Service
+	})
+	if len(summary.TopCosts) > topN {
+		summary.TopCosts = summary.TopCosts[:topN]
+	}
+
+	return summary
+}
+
+// top returns the n names with the highest counts. Names are replaced by their display name, if any.
+func top(counts map[string]int, names map[string]string, n int) []Count {
+	r := []Count{}
+	for k, v := range counts {
+		name := k
+		if d, ok := names[k]; ok {
+			name = d
+		}
+		r = append(r, Count{Name: name, Count: v})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Count != r[j].Count {
+			return r[i].Count > r[j].Count
+		}
+		return r[i].Name < r[j].Name
+	})
+	if len(r) > n {
+		r = r[:n]
+	}
+	return r
+}
+
+// cell escapes a value so it can be used in a markdown table
+func cell(v interface{}) string {
+	s := strings.ReplaceAll(fmt.Sprint(v), "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+)
+
+const (
+	production = "00000000-0000-0000-0000-000000000001"
+	test       = "00000000-0000-0000-0000-000000000002"
+)
+
+func newTestData(mask bool) *renderers.ReportData {
+	data := renderers.NewReportData("", mask)
+	data.AprlData = []azqr.AprlResult{
+		{SubscriptionID: production, SubscriptionName: "Production", ResourceType: "Microsoft.Storage/storageAccounts", Impact: azqr.ImpactHigh, Category: azqr.CategoryHighAvailability},
+		{SubscriptionID: production, SubscriptionName: "Production", ResourceType: "microsoft.storage/storageaccounts", Impact: azqr.ImpactMedium, Category: azqr.CategoryHighAvailability},
+		{SubscriptionID: test, SubscriptionName: "Test", ResourceType: "Microsoft.Web/sites", Impact: azqr.ImpactHigh, Category: azqr.CategoryDisasterRecovery},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID: production, SubscriptionName: "Production", Type: "Microsoft.Storage/storageAccounts",
+			Recommendations: map[string]azqr.AzqrResult{
+				"st-001": {Impact: azqr.ImpactLow, Category: azqr.CategorySecurity, NotCompliant: true},
+				"st-002": {Impact: azqr.ImpactHigh, Category: azqr.CategorySecurity},
+				"st-003": {RecommendationType: azqr.TypeSLA, NotCompliant: true},
+			},
+		},
+	}
+	data.DefenderData = []scanners.DefenderResult{
+		{SubscriptionID: production, SubscriptionName: "Production", Name: "VirtualMachines", Tier: "Standard"},
+		{SubscriptionID: test, SubscriptionName: "Test", Name: "StorageAccounts", Tier: "Free"},
+		{SubscriptionID: test, SubscriptionName: "Test", Name: "KubernetesService", Tier: "Free", Deprecated: true},
+	}
+	data.CostData.Items = []*scanners.CostResultItem{
+		{SubscriptionID: production, ServiceName: "Storage", Value: "10.5", Currency: "EUR"},
+		{SubscriptionID: test, ServiceName: "Storage", Value: "1.25", Currency: "EUR"},
+		{SubscriptionID: test, ServiceName: "App Service", Value: "20", Currency: "EUR"},
+		{SubscriptionID: test, ServiceName: "Invalid", Value: "n/a", Currency: "EUR"},
+	}
+	return &data
+}
+
+func TestNewSummary(t *testing.T) {
+	got := NewSummary(newTestData(true))
+
+	if got.Findings != 4 {
+		t.Errorf("NewSummary() findings = %d, want 4", got.Findings)
+	}
+	if want := []Count{{"High", 2}, {"Medium", 1}, {"Low", 1}}; !equal(got.ByImpact, want) {
+		t.Errorf("NewSummary() by impact = %v, want %v", got.ByImpact, want)
+	}
+	if want := []Count{{"High Availability", 2}, {"Disaster Recovery", 1}, {"Security", 1}}; !equal(got.ByCategory, want) {
+		t.Errorf("NewSummary() by category = %v, want %v", got.ByCategory, want)
+	}
+	if want := []Count{{"Microsoft.Storage/storageAccounts", 3}, {"Microsoft.Web/sites", 1}}; !equal(got.TopResourceTypes, want) {
+		t.Errorf("NewSummary() top resource types = %v, want %v", got.TopResourceTypes, want)
+	}
+
+	if len(got.TopSubscriptions) != 2 || got.TopSubscriptions[0].Name != "Production" || got.TopSubscriptions[0].Count != 3 {
+		t.Errorf("NewSummary() top subscriptions = %v", got.TopSubscriptions)
+	}
+	if strings.Contains(got.TopSubscriptions[0].ID, production) {
+		t.Errorf("NewSummary() subscription id = %s, want masked", got.TopSubscriptions[0].ID)
+	}
+
+	if len(got.FreeDefender) != 1 || got.FreeDefender[0].Plan != "StorageAccounts" {
+		t.Errorf("NewSummary() free defender plans = %v", got.FreeDefender)
+	}
+
+	if len(got.TopCosts) != 2 || got.TopCosts[0].Service != "App Service" || got.TopCosts[1].Value != 11.75 {
+		t.Errorf("NewSummary() top costs = %v", got.TopCosts)
+	}
+}
+
+func equal(a, b []Count) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCreateMarkdownReport(t *testing.T) {
+	dir := t.TempDir()
+	data := newTestData(false)
+	data.OutputFileName = filepath.Join(dir, "azqr_report")
+
+	if err := CreateMarkdownReport(data, ""); err != nil {
+		t.Fatalf("CreateMarkdownReport() error = %v", err)
+	}
+	content, err := os.ReadFile(data.OutputFileName + ".md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"**4** findings", "| High | 2 |", "| Production | " + production + " | 3 |", "| Test | " + test + " | StorageAccounts |", "| App Service | 20.00 EUR |"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("CreateMarkdownReport() doesn't contain %q:\n%s", want, content)
+		}
+	}
+
+	// the template can be overridden
+	template := filepath.Join(dir, "custom.md")
+	if err := os.WriteFile(template, []byte(`{{.Findings}} findings{{range .ByImpact}}, {{.Count}} {{cell .Name}}{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateMarkdownReport(data, template); err != nil {
+		t.Fatalf("CreateMarkdownReport() error = %v", err)
+	}
+	content, _ = os.ReadFile(data.OutputFileName + ".md")
+	if want := "4 findings, 2 High, 1 Medium, 1 Low"; string(content) != want {
+		t.Errorf("CreateMarkdownReport() = %q, want %q", content, want)
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.md")
+	if err := os.WriteFile(invalid, []byte(`{{range .Findings}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadTemplate(""); err != nil {
+		t.Errorf("LoadTemplate() default template error = %v", err)
+	}
+	if _, err := LoadTemplate(invalid); err == nil || !strings.Contains(err.Error(), "error parsing markdown template") {
+		t.Errorf("LoadTemplate() error = %v, want parse error", err)
+	}
+	if _, err := LoadTemplate(filepath.Join(dir, "missing.md")); err == nil {
+		t.Errorf("LoadTemplate() error = nil for a missing file")
+	}
+}
+
+func Test_cell(t *testing.T) {
+	if got := cell("a|b\nc"); got != `a\|b c` {
+		t.Errorf("cell() = %q", got)
+	}
+}
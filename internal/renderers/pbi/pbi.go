@@ -4,6 +4,8 @@
 package pbi
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -11,9 +13,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func CreatePBIReport(path string) {
+func CreatePBIReport(path string) error {
 	if path == "" {
-		log.Fatal().Msg("Please specify the path were the PowerBI template will be created using --template-path option")
+		return errors.New("please specify the path were the PowerBI template will be created using --template-path option")
 	}
 
 	log.Info().Msgf("Generating Power BI dashboard template: %sazqr.pbit", path)
@@ -21,6 +23,7 @@ func CreatePBIReport(path string) {
 	pbit := embeded.GetTemplates("azqr.pbit")
 	err := os.WriteFile(filepath.Join(path, "azqr.pbit"), []byte(pbit), 0644)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to write Power BI template: %w", err)
 	}
+	return nil
 }
@@ -15,8 +15,11 @@ import (
 
 type (
 	ReportData struct {
-		OutputFileName    string
-		Mask              bool
+		OutputFileName string
+		Mask           bool
+		// RunID identifies the scan the data belongs to, and ScannedAt is when it was run
+		RunID             string
+		ScannedAt         time.Time
 		AzqrData          []azqr.AzqrServiceResult
 		AprlData          []azqr.AprlResult
 		DefenderData      []scanners.DefenderResult
@@ -25,28 +28,21 @@ type (
 		Recomendations    map[string]map[string]azqr.AprlRecommendation
 		Resources         []*azqr.Resource
 		ResourceTypeCount []azqr.ResourceTypeCount
+		Changes           *ChangesData
+		Errors            []azqr.ScanError
+		// Suppressed holds the findings moved out of the report by the suppressions of the filters
+		Suppressed []azqr.SuppressedResult
+		// ManagementGroups holds the management group path of each subscription of a management group scan
+		ManagementGroups map[string]string
 	}
 
-	ResourceResult struct {
-		ValidationAction string `json:"validationAction"`
-		RecommendationId string `json:"recommendationId"`
-		Name             string `json:"name"`
-		Id               string `json:"id"`
-		Param1           string `json:"param1"`
-		Param2           string `json:"param2"`
-		Param3           string `json:"param3"`
-		Param4           string `json:"param4"`
-		Param5           string `json:"param5"`
-		CheckName        string `json:"checkName"`
-		Selector         string `json:"selector"`
-	}
-
-	ResourceResults struct {
-		Resource []ResourceResult `json:"Resource"`
-	}
-
-	ResourceTypeCountResults struct {
-		ResourceType []azqr.ResourceTypeCount `json:"ResourceType"`
+	// Finding - APRL finding or non-compliant AZQR recommendation, as counted by the summaries and metrics
+	Finding struct {
+		SubscriptionID   string
+		SubscriptionName string
+		ResourceType     string
+		Category         azqr.RecommendationCategory
+		Impact           azqr.RecommendationImpact
 	}
 
 	RetirementResult struct {
@@ -65,7 +61,7 @@ type (
 )
 
 func (rd *ReportData) ResourcesTable() [][]string {
-	headers := []string{"Subscription ID", "Resource Group", "Location", "Type", "Name", "Sku Name", "Sku Tier", "Kind", "SLA", "Resource ID"}
+	headers := []string{"Subscription ID", "Management Group", "Resource Group", "Location", "Type", "Name", "Sku Name", "Sku Tier", "Kind", "SLA", "Resource ID"}
 
 	rows := [][]string{}
 	for _, r := range rd.Resources {
@@ -87,6 +83,7 @@ func (rd *ReportData) ResourcesTable() [][]string {
 
 		row := []string{
 			MaskSubscriptionID(r.SubscriptionID, rd.Mask),
+			rd.ManagementGroup(r.SubscriptionID),
 			r.ResourceGroup,
 			r.Location,
 			r.Type,
@@ -95,7 +92,7 @@ func (rd *ReportData) ResourcesTable() [][]string {
 			r.SkuTier,
 			r.Kind,
 			sla,
-			r.ID,
+			MaskSubscriptionIDInResourceID(r.ID, rd.Mask),
 		}
 		rows = append(rows, row)
 	}
@@ -104,8 +101,49 @@ func (rd *ReportData) ResourcesTable() [][]string {
 	return rows
 }
 
+// Findings - Returns the APRL findings and the non-compliant AZQR recommendations.
+// AZQR results of other types, like the SLA of a resource, are not findings.
+func (rd *ReportData) Findings() []Finding {
+	findings := []Finding{}
+	for _, r := range rd.AprlData {
+		findings = append(findings, Finding{
+			SubscriptionID:   r.SubscriptionID,
+			SubscriptionName: r.SubscriptionName,
+			ResourceType:     r.ResourceType,
+			Category:         r.Category,
+			Impact:           r.Impact,
+		})
+	}
+
+	for _, d := range rd.AzqrData {
+		for _, r := range d.Recommendations {
+			if r.NotCompliant && r.RecommendationType == azqr.TypeRecommendation {
+				findings = append(findings, Finding{
+					SubscriptionID:   d.SubscriptionID,
+					SubscriptionName: d.SubscriptionName,
+					ResourceType:     d.Type,
+					Category:         r.Category,
+					Impact:           r.Impact,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// CountFindings - Returns the number of findings with the given impact or higher
+func (rd *ReportData) CountFindings(impact azqr.RecommendationImpact) int {
+	count := 0
+	for _, f := range rd.Findings() {
+		if f.Impact.AtLeast(impact) {
+			count++
+		}
+	}
+	return count
+}
+
 func (rd *ReportData) ImpactedTable() [][]string {
-	headers := []string{"Validated Using", "Source", "Category", "Impact", "Resource Type", "Recommendation", "Recommendation Id", "Subscription Id", "Subscription Name", "Resource Group", "Name", "Id", "Param1", "Param2", "Param3", "Param4", "Param5", "Learn"}
+	headers := []string{"Validated Using", "Source", "Category", "Impact", "Resource Type", "Recommendation", "Recommendation Id", "Subscription Id", "Subscription Name", "Management Group", "Resource Group", "Name", "Id", "Param1", "Param2", "Param3", "Param4", "Param5", "Learn"}
 
 	rows := [][]string{}
 	for _, r := range rd.AprlData {
@@ -119,6 +157,7 @@ func (rd *ReportData) ImpactedTable() [][]string {
 			r.RecommendationID,
 			MaskSubscriptionID(r.SubscriptionID, rd.Mask),
 			r.SubscriptionName,
+			rd.ManagementGroup(r.SubscriptionID),
 			r.ResourceGroup,
 			r.Name,
 			MaskSubscriptionIDInResourceID(r.ResourceID, rd.Mask),
@@ -145,6 +184,7 @@ func (rd *ReportData) ImpactedTable() [][]string {
 					r.RecommendationID,
 					MaskSubscriptionID(d.SubscriptionID, rd.Mask),
 					d.SubscriptionName,
+					rd.ManagementGroup(d.SubscriptionID),
 					d.ResourceGroup,
 					d.ServiceName,
 					MaskSubscriptionIDInResourceID(d.ResourceID(), rd.Mask),
@@ -215,7 +255,7 @@ func (rd *ReportData) AdvisorTable() [][]string {
 			d.Category,
 			d.Impact,
 			d.Description,
-			d.ResourceID,
+			MaskSubscriptionIDInResourceID(d.ResourceID, rd.Mask),
 			d.RecommendationID,
 		}
 		rows = append(rows, row)
@@ -225,7 +265,8 @@ func (rd *ReportData) AdvisorTable() [][]string {
 	return rows
 }
 
-func (rd *ReportData) RecommendationsTable() [][]string {
+// ImpactedCount - Returns the number of impacted resources of each recommendation of the report
+func (rd *ReportData) ImpactedCount() map[string]int {
 	counter := map[string]int{}
 	for _, rt := range rd.Recomendations {
 		for _, r := range rt {
@@ -244,6 +285,22 @@ func (rd *ReportData) RecommendationsTable() [][]string {
 			}
 		}
 	}
+	return counter
+}
+
+// RecommendationSource - Returns the source of a recommendation: AZQR, APRL or CUSTOM
+func RecommendationSource(r azqr.AprlRecommendation) string {
+	if r.Source != "" {
+		return r.Source
+	}
+	if _, err := uuid.Parse(r.RecommendationID); err != nil {
+		return "AZQR"
+	}
+	return "APRL"
+}
+
+func (rd *ReportData) RecommendationsTable() [][]string {
+	counter := rd.ImpactedCount()
 
 	headers := []string{"Implemented", "Number of Impacted Resources", "Azure Service / Well-Architected", "Recommendation Source",
 		"Azure Service Category / Well-Architected Area", "Azure Service / Well-Architected Topic", "Resiliency Category", "Recommendation",
@@ -252,11 +309,7 @@ func (rd *ReportData) RecommendationsTable() [][]string {
 	for _, rt := range rd.Recomendations {
 		for _, r := range rt {
 			implemented := counter[r.RecommendationID] == 0
-			source := "APRL"
-			_, err := uuid.Parse(r.RecommendationID)
-			if err != nil {
-				source = "AZQR"
-			}
+			source := RecommendationSource(r)
 
 			categoryPart := ""
 			servicePart := ""
@@ -308,6 +361,63 @@ func (rd *ReportData) ResourceTypesTable() [][]string {
 	return rows
 }
 
+func (rd *ReportData) ErrorsTable() [][]string {
+	headers := []string{"Subscription", "Subscription Name", "Component", "Error"}
+	rows := [][]string{}
+	for _, e := range rd.MaskedErrors() {
+		row := []string{
+			e.SubscriptionID,
+			e.SubscriptionName,
+			e.Component,
+			e.Message,
+		}
+		rows = append(rows, row)
+	}
+
+	rows = append([][]string{headers}, rows...)
+	return rows
+}
+
+// SuppressedTable - Returns the suppressed findings with the justification, owner and expiry of their suppression,
+// or the opt-out tag of their resource
+func (rd *ReportData) SuppressedTable() [][]string {
+	headers := []string{"Source", "Category", "Impact", "Resource Type", "Recommendation", "Recommendation Id", "Subscription Id", "Subscription Name", "Resource Group", "Name", "Id", "Justification", "Owner", "Expires", "Opt-out Tag"}
+	rows := [][]string{}
+	for _, r := range rd.Suppressed {
+		row := []string{
+			r.Source,
+			string(r.Category),
+			string(r.Impact),
+			r.ResourceType,
+			r.Recommendation,
+			r.RecommendationID,
+			MaskSubscriptionID(r.SubscriptionID, rd.Mask),
+			r.SubscriptionName,
+			r.ResourceGroup,
+			r.Name,
+			MaskSubscriptionIDInResourceID(r.ResourceID, rd.Mask),
+			r.Justification,
+			r.Owner,
+			r.Expires,
+			r.Tag,
+		}
+		rows = append(rows, row)
+	}
+
+	rows = append([][]string{headers}, rows...)
+	return rows
+}
+
+// MaskedErrors - Returns the scan errors with the subscription ids masked
+func (rd *ReportData) MaskedErrors() []azqr.ScanError {
+	errors := []azqr.ScanError{}
+	for _, e := range rd.Errors {
+		e.SubscriptionID = MaskSubscriptionID(e.SubscriptionID, rd.Mask)
+		errors = append(errors, e)
+	}
+	return errors
+}
+
 func (rd *ReportData) ResourceIDs() []*string {
 	ids := []*string{}
 	for _, r := range rd.Resources {
@@ -321,6 +431,8 @@ func NewReportData(outputFile string, mask bool) ReportData {
 	return ReportData{
 		OutputFileName: outputFile,
 		Mask:           mask,
+		RunID:          uuid.NewString(),
+		ScannedAt:      time.Now().UTC(),
 		Recomendations: map[string]map[string]azqr.AprlRecommendation{},
 		AzqrData:       []azqr.AzqrServiceResult{},
 		AprlData:       []azqr.AprlResult{},
@@ -329,10 +441,19 @@ func NewReportData(outputFile string, mask bool) ReportData {
 		CostData: &scanners.CostResult{
 			Items: []*scanners.CostResultItem{},
 		},
+		Resources:         []*azqr.Resource{},
 		ResourceTypeCount: []azqr.ResourceTypeCount{},
+		Errors:            []azqr.ScanError{},
+		Suppressed:        []azqr.SuppressedResult{},
+		ManagementGroups:  map[string]string{},
 	}
 }
 
+// ManagementGroup - Returns the management group path of the subscription, or empty if the scan wasn't scoped to a management group
+func (rd *ReportData) ManagementGroup(subscriptionID string) string {
+	return rd.ManagementGroups[strings.ToLower(subscriptionID)]
+}
+
 func MaskSubscriptionID(subscriptionID string, mask bool) string {
 	if len(subscriptionID) < 36 {
 		return ""
@@ -347,7 +468,7 @@ func MaskSubscriptionID(subscriptionID string, mask bool) string {
 }
 
 func MaskSubscriptionIDInResourceID(resourceID string, mask bool) string {
-	if !strings.HasPrefix(resourceID, "/subscriptions/") {
+	if !strings.HasPrefix(strings.ToLower(resourceID), "/subscriptions/") {
 		return ""
 	}
 
@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package renderers
+
+import (
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+)
+
+func TestReportData_CountFindings(t *testing.T) {
+	data := NewReportData("", true)
+	data.AprlData = []azqr.AprlResult{{Impact: azqr.ImpactHigh}, {Impact: azqr.ImpactLow}}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			Recommendations: map[string]azqr.AzqrResult{
+				"a": {RecommendationType: azqr.TypeRecommendation, Impact: azqr.ImpactMedium, NotCompliant: true},
+				"b": {RecommendationType: azqr.TypeRecommendation, Impact: azqr.ImpactHigh, NotCompliant: false},
+				"c": {RecommendationType: azqr.TypeRecommendation, Impact: azqr.ImpactLow, NotCompliant: true},
+				// the SLA of a resource is not a finding, as in the summary and the metrics
+				"d": {RecommendationType: azqr.TypeSLA, Impact: azqr.ImpactHigh, NotCompliant: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		impact azqr.RecommendationImpact
+		want   int
+	}{
+		{impact: azqr.ImpactHigh, want: 1},
+		{impact: azqr.ImpactMedium, want: 2},
+		{impact: azqr.ImpactLow, want: 4},
+	}
+	for _, tt := range tests {
+		if got := data.CountFindings(tt.impact); got != tt.want {
+			t.Errorf("ReportData.CountFindings(%s) = %v, want %v", tt.impact, got, tt.want)
+		}
+	}
+
+	if got := len(data.Findings()); got != 4 {
+		t.Errorf("ReportData.Findings() = %v findings, want 4", got)
+	}
+}
+
+func TestReportData_ManagementGroup(t *testing.T) {
+	sid := "00000000-0000-0000-0000-00000000000A"
+	data := NewReportData("", false)
+	data.ManagementGroups = map[string]string{"00000000-0000-0000-0000-00000000000a": "Corp/Online"}
+	data.Resources = []*azqr.Resource{{ID: "/subscriptions/" + sid + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/st", SubscriptionID: sid}}
+	data.AprlData = []azqr.AprlResult{{SubscriptionID: sid}}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{SubscriptionID: sid, Recommendations: map[string]azqr.AzqrResult{"a": {NotCompliant: true}}},
+	}
+
+	inventory := data.ResourcesTable()
+	if inventory[0][1] != "Management Group" || inventory[1][1] != "Corp/Online" {
+		t.Errorf("ReportData.ResourcesTable() = %v, want the management group path", inventory)
+	}
+
+	impacted := data.ImpactedTable()
+	for _, row := range impacted[1:] {
+		if impacted[0][9] != "Management Group" || row[9] != "Corp/Online" {
+			t.Errorf("ReportData.ImpactedTable() = %v, want the management group path", impacted)
+		}
+	}
+
+	if got := data.ManagementGroup("00000000-0000-0000-0000-000000000001"); got != "" {
+		t.Errorf("ReportData.ManagementGroup() = %v, want empty", got)
+	}
+}
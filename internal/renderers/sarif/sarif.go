@@ -0,0 +1,290 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// fingerprintKey - Name of the partial fingerprint of the results
+	fingerprintKey = "azqrFinding/v1"
+	// Version - SARIF version of the report
+	Version = "2.1.0"
+	// Schema - JSON schema of the SARIF version of the report
+	Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type (
+	// Log - SARIF log with a single run of azqr
+	Log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []Run  `json:"runs"`
+	}
+
+	// Run - Recommendations evaluated by azqr and the findings of the scan
+	Run struct {
+		Tool    Tool     `json:"tool"`
+		Results []Result `json:"results"`
+	}
+
+	Tool struct {
+		Driver Driver `json:"driver"`
+	}
+
+	Driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []Rule `json:"rules"`
+	}
+
+	// Rule - AZQR or APRL recommendation
+	Rule struct {
+		ID                   string         `json:"id"`
+		ShortDescription     Message        `json:"shortDescription"`
+		FullDescription      Message        `json:"fullDescription"`
+		HelpURI              string         `json:"helpUri,omitempty"`
+		DefaultConfiguration Configuration  `json:"defaultConfiguration"`
+		Properties           RuleProperties `json:"properties"`
+	}
+
+	Configuration struct {
+		Level string `json:"level"`
+	}
+
+	// RuleProperties - Category and impact of a recommendation. Code scanning tools use security-severity to rank the findings.
+	RuleProperties struct {
+		Tags             []string `json:"tags"`
+		SecuritySeverity string   `json:"security-severity"`
+	}
+
+	// Result - Resource that doesn't comply with a recommendation.
+	// The fingerprint identifies the finding across runs, so code scanning tools can track its alert.
+	Result struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             Message           `json:"message"`
+		Locations           []Location        `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+	}
+
+	Message struct {
+		Text string `json:"text"`
+	}
+
+	// Location - Azure resource of a finding. Code scanning tools require a physical location,
+	// so the resource id is also used as the uri of a synthetic artifact.
+	Location struct {
+		PhysicalLocation PhysicalLocation  `json:"physicalLocation"`
+		LogicalLocations []LogicalLocation `json:"logicalLocations"`
+	}
+
+	PhysicalLocation struct {
+		ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+		Region           Region           `json:"region"`
+	}
+
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	Region struct {
+		StartLine int `json:"startLine"`
+	}
+
+	// LogicalLocation - Azure resource of a finding
+	LogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// CreateSarifReport - Writes the findings of the scan to <OutputFileName>.sarif
+func CreateSarifReport(data *renderers.ReportData) error {
+	filename := fmt.Sprintf("%s.sarif", data.OutputFileName)
+	log.Info().Msgf("Generating Report: %s", filename)
+
+	js, err := json.MarshalIndent(NewLog(data), "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling sarif: %w", err)
+	}
+
+	if err := os.WriteFile(filename, js, 0644); err != nil {
+		return fmt.Errorf("error writing sarif: %w", err)
+	}
+	return nil
+}
+
+// NewLog - Converts the recommendations to SARIF rules and the non-compliant resources to SARIF results
+func NewLog(data *renderers.ReportData) Log {
+	// the ids are sorted, so the rules and results are the same in every run
+	rules := []Rule{}
+	types := make([]string, 0, len(data.Recomendations))
+	for t := range data.Recomendations {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		rt := data.Recomendations[t]
+		ids := make([]string, 0, len(rt))
+		for id := range rt {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			r := rt[id]
+			learn := ""
+			if len(r.LearnMoreLink) > 0 {
+				learn = r.LearnMoreLink[0].Url
+			}
+			rules = append(rules, newRule(r.RecommendationID, r.Recommendation, r.LongDescription, learn, r.Category, r.Impact))
+		}
+	}
+
+	results := []Result{}
+	for _, r := range data.AprlData {
+		rules = append(rules, newRule(r.RecommendationID, r.Recommendation, r.LongDescription, r.Learn, string(r.Category), string(r.Impact)))
+		results = append(results, newResult(r.RecommendationID, r.Impact, r.Recommendation, r.Name, r.ResourceID, data.Mask))
+	}
+
+	for _, d := range data.AzqrData {
+		ids := make([]string, 0, len(d.Recommendations))
+		for id := range d.Recommendations {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			r := d.Recommendations[id]
+			if !r.NotCompliant {
+				continue
+			}
+
+			rules = append(rules, newRule(r.RecommendationID, r.Recommendation, "", r.LearnMoreUrl, string(r.Category), string(r.Impact)))
+			message := r.Recommendation
+			if r.Result != "" {
+				message = fmt.Sprintf("%s: %s", r.Recommendation, r.Result)
+			}
+			results = append(results, newResult(r.RecommendationID, r.Impact, message, d.ServiceName, d.ResourceID(), data.Mask))
+		}
+	}
+
+	// keep the first rule with each id. Results reference their rule by index.
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	index := map[string]int{}
+	unique := []Rule{}
+	for _, r := range rules {
+		if _, ok := index[r.ID]; ok {
+			continue
+		}
+		index[r.ID] = len(unique)
+		unique = append(unique, r)
+	}
+
+	for i := range results {
+		results[i].RuleIndex = index[results[i].RuleID]
+	}
+
+	return Log{
+		Schema:  Schema,
+		Version: Version,
+		Runs: []Run{
+			{
+				Tool: Tool{
+					Driver: Driver{
+						Name:           "azqr",
+						InformationURI: "https://azure.github.io/azqr",
+						Rules:          unique,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func newRule(id, recommendation, description, learn, category, impact string) Rule {
+	if description == "" {
+		description = recommendation
+	}
+
+	return Rule{
+		ID:                   id,
+		ShortDescription:     Message{Text: recommendation},
+		FullDescription:      Message{Text: description},
+		HelpURI:              learn,
+		DefaultConfiguration: Configuration{Level: level(azqr.RecommendationImpact(impact))},
+		Properties: RuleProperties{
+			Tags:             []string{category, impact},
+			SecuritySeverity: securitySeverity(azqr.RecommendationImpact(impact)),
+		},
+	}
+}
+
+func newResult(id string, impact azqr.RecommendationImpact, message, name, resourceID string, mask bool) Result {
+	masked := renderers.MaskSubscriptionIDInResourceID(resourceID, mask)
+	return Result{
+		RuleID:  id,
+		Level:   level(impact),
+		Message: Message{Text: message},
+		Locations: []Location{
+			{
+				PhysicalLocation: PhysicalLocation{
+					ArtifactLocation: ArtifactLocation{URI: strings.TrimPrefix(masked, "/")},
+					Region:           Region{StartLine: 1},
+				},
+				LogicalLocations: []LogicalLocation{
+					{Name: name, FullyQualifiedName: masked, Kind: "resource"},
+				},
+			},
+		},
+		PartialFingerprints: map[string]string{fingerprintKey: fingerprint(resourceID, id)},
+	}
+}
+
+// fingerprint returns a stable hash of a finding. It uses the unmasked resource id, so it doesn't change with --mask.
+func fingerprint(resourceID, recommendationID string) string {
+	h := sha256.Sum256([]byte(strings.ToLower(resourceID) + "|" + strings.ToLower(recommendationID)))
+	return hex.EncodeToString(h[:])
+}
+
+// level maps the impact of a recommendation to the SARIF level of its results
+func level(impact azqr.RecommendationImpact) string {
+	switch strings.ToLower(string(impact)) {
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity maps the impact of a recommendation to the severity score used by code scanning tools
+func securitySeverity(impact azqr.RecommendationImpact) string {
+	switch strings.ToLower(string(impact)) {
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
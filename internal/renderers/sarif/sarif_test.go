@@ -0,0 +1,164 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000001"
+
+func newTestData(mask bool) *renderers.ReportData {
+	data := renderers.NewReportData("", mask)
+	aprl := azqr.AprlRecommendation{
+		RecommendationID: "11111111-1111-1111-1111-111111111111",
+		Recommendation:   "Use zone redundant storage",
+		LongDescription:  "Zone redundant storage replicates data across zones",
+		Category:         string(azqr.CategoryHighAvailability),
+		Impact:           string(azqr.ImpactHigh),
+		ResourceType:     "Microsoft.Storage/storageAccounts",
+	}
+	aprl.LearnMoreLink = append(aprl.LearnMoreLink, struct {
+		Name string `yaml:"name"`
+		Url  string `yaml:"url"`
+	}{Name: "Learn", Url: "https://learn.microsoft.com/zrs"})
+
+	data.Recomendations = map[string]map[string]azqr.AprlRecommendation{
+		"microsoft.storage/storageaccounts": {aprl.RecommendationID: aprl},
+	}
+	data.AprlData = []azqr.AprlResult{
+		{
+			RecommendationID: aprl.RecommendationID,
+			Recommendation:   aprl.Recommendation,
+			Category:         azqr.CategoryHighAvailability,
+			Impact:           azqr.ImpactHigh,
+			Name:             "data",
+			ResourceID:       "/subscriptions/" + subscriptionID + "/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/data",
+			Learn:            "https://learn.microsoft.com/zrs",
+		},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID: subscriptionID,
+			ResourceGroup:  "rg",
+			Type:           "Microsoft.Web/sites",
+			ServiceName:    "app",
+			Recommendations: map[string]azqr.AzqrResult{
+				"app-001": {RecommendationID: "app-001", Recommendation: "App Service should use TLS 1.2", Category: azqr.CategorySecurity, Impact: azqr.ImpactMedium, NotCompliant: true, Result: "TLS 1.0"},
+				"app-002": {RecommendationID: "app-002", Recommendation: "App Service should use HTTPS only", Category: azqr.CategorySecurity, Impact: azqr.ImpactLow, NotCompliant: false},
+			},
+		},
+	}
+	return &data
+}
+
+func TestNewLog(t *testing.T) {
+	log := NewLog(newTestData(true))
+
+	if log.Version != Version || len(log.Runs) != 1 {
+		t.Fatalf("NewLog() = %+v", log)
+	}
+	run := log.Runs[0]
+
+	// rules are sorted and unique, compliant recommendations that are not in the catalogue are not reported
+	ids := []string{}
+	for _, r := range run.Tool.Driver.Rules {
+		ids = append(ids, r.ID)
+	}
+	if want := "11111111-1111-1111-1111-111111111111,app-001"; strings.Join(ids, ",") != want {
+		t.Errorf("NewLog() rules = %v, want %v", ids, want)
+	}
+
+	aprl := run.Tool.Driver.Rules[0]
+	if aprl.DefaultConfiguration.Level != "error" || aprl.Properties.SecuritySeverity != "8.0" ||
+		strings.Join(aprl.Properties.Tags, ",") != "High Availability,High" || aprl.HelpURI != "https://learn.microsoft.com/zrs" {
+		t.Errorf("NewLog() rule = %+v", aprl)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("NewLog() results = %+v, want 2", run.Results)
+	}
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("NewLog() result %s references rule %d", r.RuleID, r.RuleIndex)
+		}
+		location := r.Locations[0].LogicalLocations[0]
+		if strings.Contains(location.FullyQualifiedName, subscriptionID) || !strings.HasPrefix(location.FullyQualifiedName, "/subscriptions/xxxxxxxx-") {
+			t.Errorf("NewLog() location = %s, want masked subscription", location.FullyQualifiedName)
+		}
+		// code scanning drops the results without a physical location
+		if uri := r.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != strings.TrimPrefix(location.FullyQualifiedName, "/") {
+			t.Errorf("NewLog() artifact uri = %s, want %s", uri, location.FullyQualifiedName)
+		}
+	}
+
+	finding := run.Results[1]
+	if finding.RuleID != "app-001" || finding.Level != "warning" || finding.Message.Text != "App Service should use TLS 1.2: TLS 1.0" {
+		t.Errorf("NewLog() result = %+v", finding)
+	}
+}
+
+func TestCreateSarifReport(t *testing.T) {
+	data := newTestData(false)
+	data.OutputFileName = filepath.Join(t.TempDir(), "azqr_report")
+
+	if err := CreateSarifReport(data); err != nil {
+		t.Fatalf("CreateSarifReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(data.OutputFileName + ".sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Log{}
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("CreateSarifReport() wrote invalid json: %v", err)
+	}
+	if got.Schema != Schema || !strings.Contains(got.Runs[0].Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName, subscriptionID) {
+		t.Errorf("CreateSarifReport() = %s", content)
+	}
+}
+
+func TestNewLog_Fingerprints(t *testing.T) {
+	masked := NewLog(newTestData(true)).Runs[0].Results
+	unmasked := NewLog(newTestData(false)).Runs[0].Results
+
+	seen := map[string]bool{}
+	for i, r := range masked {
+		fp := r.PartialFingerprints[fingerprintKey]
+		if fp == "" || seen[fp] {
+			t.Errorf("NewLog() fingerprint of %s = %q, want a unique fingerprint", r.RuleID, fp)
+		}
+		seen[fp] = true
+
+		// masking doesn't change the fingerprints, so alerts are tracked across runs
+		if other := unmasked[i].PartialFingerprints[fingerprintKey]; other != fp {
+			t.Errorf("NewLog() fingerprint = %s with mask, %s without", fp, other)
+		}
+	}
+}
+
+func TestNewLog_Stable(t *testing.T) {
+	data := newTestData(true)
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("app-1%02d", i)
+		data.AzqrData[0].Recommendations[id] = azqr.AzqrResult{RecommendationID: id, Recommendation: id, NotCompliant: true}
+	}
+
+	want, _ := json.Marshal(NewLog(data))
+	for i := 0; i < 10; i++ {
+		if got, _ := json.Marshal(NewLog(data)); string(got) != string(want) {
+			t.Fatalf("NewLog() changed between runs:\n%s\n%s", got, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Kind - Identifies a file as an azqr scan snapshot
+	Kind = "azqr.snapshot"
+	// Version - Current version of the snapshot format
+	Version = 1
+)
+
+type (
+	// Snapshot - Complete, unmasked result of a scan. Reports can be rendered from it without access to Azure.
+	Snapshot struct {
+		Kind              string                                        `json:"kind"`
+		Version           int                                           `json:"version"`
+		CreatedAt         time.Time                                     `json:"createdAt"`
+		RunID             string                                        `json:"runId,omitempty"`
+		ScannedAt         time.Time                                     `json:"scannedAt,omitempty"`
+		AzqrData          []azqr.AzqrServiceResult                      `json:"azqrData"`
+		AprlData          []azqr.AprlResult                             `json:"aprlData"`
+		DefenderData      []scanners.DefenderResult                     `json:"defenderData"`
+		AdvisorData       []scanners.AdvisorResult                      `json:"advisorData"`
+		CostData          *scanners.CostResult                          `json:"costData"`
+		Recomendations    map[string]map[string]azqr.AprlRecommendation `json:"recommendations"`
+		Resources         []*azqr.Resource                              `json:"resources"`
+		ResourceTypeCount []azqr.ResourceTypeCount                      `json:"resourceTypeCount"`
+		Errors            []azqr.ScanError                              `json:"errors"`
+		Suppressed        []azqr.SuppressedResult                       `json:"suppressed,omitempty"`
+		ManagementGroups  map[string]string                             `json:"managementGroups,omitempty"`
+	}
+)
+
+// CreateSnapshot - Writes the report data to <OutputFileName>.snapshot.json
+func CreateSnapshot(data *renderers.ReportData) error {
+	filename := fmt.Sprintf("%s.snapshot.json", data.OutputFileName)
+	log.Info().Msgf("Generating Snapshot: %s", filename)
+
+	s := Snapshot{
+		Kind:              Kind,
+		Version:           Version,
+		CreatedAt:         time.Now().UTC(),
+		RunID:             data.RunID,
+		ScannedAt:         data.ScannedAt,
+		AzqrData:          data.AzqrData,
+		AprlData:          data.AprlData,
+		DefenderData:      data.DefenderData,
+		AdvisorData:       data.AdvisorData,
+		CostData:          data.CostData,
+		Recomendations:    data.Recomendations,
+		Resources:         data.Resources,
+		ResourceTypeCount: data.ResourceTypeCount,
+		Errors:            data.Errors,
+		Suppressed:        data.Suppressed,
+		ManagementGroups:  data.ManagementGroups,
+	}
+
+	js, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling snapshot: %w", err)
+	}
+
+	err = os.WriteFile(filename, js, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot - Reads a snapshot file and returns the report data it contains
+func LoadSnapshot(snapshotFile string, outputFile string, mask bool) (*renderers.ReportData, error) {
+	content, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Snapshot{}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("failed parsing snapshot %s: %w", snapshotFile, err)
+	}
+
+	if s.Kind != Kind {
+		return nil, fmt.Errorf("%s is not an azqr snapshot", snapshotFile)
+	}
+
+	if s.Version < 1 || s.Version > Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d. This version of azqr supports up to version %d", s.Version, Version)
+	}
+
+	data := renderers.NewReportData(outputFile, mask)
+	// reports rendered again from the snapshot belong to the same scan run
+	if s.RunID != "" {
+		data.RunID = s.RunID
+		data.ScannedAt = s.ScannedAt
+	}
+	if s.AzqrData != nil {
+		data.AzqrData = s.AzqrData
+	}
+	if s.AprlData != nil {
+		data.AprlData = s.AprlData
+	}
+	if s.DefenderData != nil {
+		data.DefenderData = s.DefenderData
+	}
+	if s.AdvisorData != nil {
+		data.AdvisorData = s.AdvisorData
+	}
+	if s.CostData != nil {
+		data.CostData = s.CostData
+	}
+	if s.Recomendations != nil {
+		data.Recomendations = s.Recomendations
+	}
+	if s.Resources != nil {
+		data.Resources = s.Resources
+	}
+	if s.ResourceTypeCount != nil {
+		data.ResourceTypeCount = s.ResourceTypeCount
+	}
+	if s.Errors != nil {
+		data.Errors = s.Errors
+	}
+	if s.Suppressed != nil {
+		data.Suppressed = s.Suppressed
+	}
+	if s.ManagementGroups != nil {
+		data.ManagementGroups = s.ManagementGroups
+	}
+
+	return &data, nil
+}
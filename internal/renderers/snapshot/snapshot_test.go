@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/Azure/azqr/internal/scanners"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	data := renderers.NewReportData(filepath.Join(dir, "azqr_report"), true)
+	data.AzqrData = []azqr.AzqrServiceResult{
+		{
+			SubscriptionID: "00000000-0000-0000-0000-000000000000", ResourceGroup: "rg", Type: "Microsoft.Web/sites", ServiceName: "app",
+			Recommendations: map[string]azqr.AzqrResult{"app-001": {RecommendationID: "app-001", NotCompliant: true, Impact: azqr.ImpactHigh}},
+		},
+	}
+	data.AprlData = []azqr.AprlResult{{RecommendationID: "aprl-1", ResourceID: "/subscriptions/s/resourceGroups/rg", Source: "APRL"}}
+	data.DefenderData = []scanners.DefenderResult{{SubscriptionID: "s", Name: "VirtualMachines", Tier: "Standard"}}
+	data.AdvisorData = []scanners.AdvisorResult{{SubscriptionID: "s", Name: "app", Impact: "High"}}
+	data.CostData = &scanners.CostResult{
+		From:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:    time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Items: []*scanners.CostResultItem{{SubscriptionID: "s", ServiceName: "Storage", Value: "1.5", Currency: "EUR"}},
+	}
+	data.Recomendations = map[string]map[string]azqr.AprlRecommendation{"microsoft.web/sites": {"app-001": {RecommendationID: "app-001"}}}
+	data.Resources = []*azqr.Resource{{ID: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/app", Name: "app"}}
+	data.ResourceTypeCount = []azqr.ResourceTypeCount{{Subscription: "s", ResourceType: "Microsoft.Web/sites", Count: 1}}
+	data.Errors = []azqr.ScanError{{SubscriptionID: "s", Component: "Advisor", Message: "forbidden"}}
+	data.ManagementGroups = map[string]string{"00000000-0000-0000-0000-000000000000": "Corp/Online"}
+
+	if err := CreateSnapshot(&data); err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+
+	got, err := LoadSnapshot(data.OutputFileName+".snapshot.json", "rendered", false)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	// the output file and masking are set when rendering
+	if got.OutputFileName != "rendered" || got.Mask {
+		t.Errorf("LoadSnapshot() output = %s, mask = %v", got.OutputFileName, got.Mask)
+	}
+	got.OutputFileName, got.Mask = data.OutputFileName, data.Mask
+	if !reflect.DeepEqual(*got, data) {
+		t.Errorf("LoadSnapshot() = %+v, want %+v", *got, data)
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty snapshot", content: `{"kind":"azqr.snapshot","version":1}`},
+		{name: "not a snapshot", content: `{"kind":"other","version":1}`, wantErr: "is not an azqr snapshot"},
+		{name: "newer version", content: `{"kind":"azqr.snapshot","version":2}`, wantErr: "unsupported snapshot version 2"},
+		{name: "missing version", content: `{"kind":"azqr.snapshot"}`, wantErr: "unsupported snapshot version 0"},
+		{name: "invalid json", content: `{"kind":`, wantErr: "failed parsing snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "azqr.snapshot.json")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadSnapshot(file, "out", true)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadSnapshot() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSnapshot() error = %v", err)
+			}
+			// missing sections are left empty, so the renderers never see nil data
+			if got.Resources == nil || got.AzqrData == nil || got.CostData == nil || got.Errors == nil {
+				t.Errorf("LoadSnapshot() returned nil sections: %+v", got)
+			}
+		})
+	}
+}
@@ -5,16 +5,24 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/checkpoint"
+	"github.com/Azure/azqr/internal/metrics"
+	"github.com/Azure/azqr/internal/notify"
 	"github.com/Azure/azqr/internal/renderers"
-	"github.com/Azure/azqr/internal/renderers/csv"
 	"github.com/Azure/azqr/internal/renderers/excel"
-	"github.com/Azure/azqr/internal/renderers/json"
+	"github.com/Azure/azqr/internal/renderers/markdown"
+	"github.com/Azure/azqr/internal/renderers/snapshot"
 	"github.com/Azure/azqr/internal/scanners"
+	"github.com/Azure/azqr/internal/throttling"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
@@ -24,10 +32,14 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 )
 
+// DefaultSubscriptionConcurrency - Default number of subscriptions scanned at the same time
+const DefaultSubscriptionConcurrency = 8
+
 type (
 	ScanParams struct {
 		SubscriptionID          string
 		ResourceGroup           string
+		ManagementGroup         string
 		OutputName              string
 		Defender                bool
 		Advisor                 bool
@@ -35,18 +47,48 @@ type (
 		Mask                    bool
 		Csv                     bool
 		Json                    bool
+		Sarif                   bool
+		JUnit                   bool
+		Html                    bool
+		Markdown                bool
+		MarkdownTemplate        string
+		Ndjson                  bool
+		Parquet                 bool
+		ExcelProfile            string
+		IgnoreTag               string
+		FailOnImpact            string
+		MetricsEndpoint         string
+		MetricsProtocol         string
+		WebhookURL              string
+		WebhookTemplate         string
+		WebhookHeaders          []string
+		WebhookBaseline         string
+		WebhookTop              int
+		WebhookRetries          int
+		WebhookDryRun           bool
+		Snapshot                bool
+		FailFast                bool
+		MaxConcurrency          int
+		SubscriptionConcurrency int
+		StateDir                string
+		Resume                  bool
 		Debug                   bool
-		ServiceScanners         []azqr.IAzureScanner
+		ServiceScanners         func() []azqr.IAzureScanner
 		ForceAzureCliCredential bool
 		FilterFile              string
+		RulesDir                string
+		AprlRulesDir            string
 		UseAzqrRecommendations  bool
 		UseAprlRecommendations  bool
+		BatchAprlQueries        bool
 	}
 
 	Scanner struct{}
 )
 
-func (sc Scanner) Scan(params *ScanParams) {
+// Scan scans the Azure resources and renders the reports.
+// Failures of individual components are recorded in the report and returned, unless FailFast is set. In that case the first failure aborts the scan.
+func (sc Scanner) Scan(params *ScanParams) ([]azqr.ScanError, error) {
 	// Default level for this example is info, unless debug flag is present
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	if params.Debug {
@@ -55,14 +97,67 @@ func (sc Scanner) Scan(params *ScanParams) {
 	}
 
 	// generate output file name
-	outputFile := sc.generateOutputFileName(params.OutputName)
+	outputFile := generateOutputFileName(params.OutputName)
 
 	// load filters
-	filters := azqr.LoadFilters(params.FilterFile)
+	filters, err := azqr.LoadFilters(params.FilterFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// load custom rules
+	if err := azqr.LoadCustomRules(params.RulesDir, scanners.GetScanners()); err != nil {
+		return nil, err
+	}
+
+	// load APRL recommendations, including the custom ones
+	aprlScanner := AprlScanner{BatchQueries: params.BatchAprlQueries, RulesDir: params.AprlRulesDir}
+	aprl, err := aprlScanner.GetAprlRecommendations()
+	if err != nil {
+		return nil, err
+	}
 
 	// validate input
 	if params.SubscriptionID == "" && params.ResourceGroup != "" {
-		log.Fatal().Msg("Resource Group name can only be used with a Subscription Id")
+		return nil, errors.New("resource group name can only be used with a subscription id")
+	}
+
+	if params.Markdown {
+		if _, err := markdown.LoadTemplate(params.MarkdownTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := excel.LoadProfile(params.ExcelProfile); err != nil {
+		return nil, err
+	}
+
+	var failOnImpact azqr.RecommendationImpact
+	if params.FailOnImpact != "" {
+		if failOnImpact, err = azqr.ParseImpact(params.FailOnImpact); err != nil {
+			return nil, err
+		}
+	}
+
+	var exporter metrics.Exporter
+	if params.MetricsEndpoint != "" {
+		if exporter, err = metrics.NewExporter(params.MetricsProtocol, params.MetricsEndpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	var webhook *notify.Webhook
+	var baseline *renderers.ReportData
+	if params.WebhookURL != "" || params.WebhookDryRun {
+		if webhook, err = notify.NewWebhook(params.WebhookURL, params.WebhookTemplate, params.WebhookHeaders, params.WebhookRetries, params.WebhookDryRun); err != nil {
+			return nil, err
+		}
+
+		if params.WebhookBaseline != "" {
+			if baseline, err = snapshot.LoadSnapshot(params.WebhookBaseline, "", params.Mask); err != nil {
+				return nil, fmt.Errorf("failed to load snapshot %s: %w", params.WebhookBaseline, err)
+			}
+		}
 	}
 
 	if params.SubscriptionID != "" {
@@ -73,8 +168,17 @@ func (sc Scanner) Scan(params *ScanParams) {
 		filters.Azqr.AddResourceGroup(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", params.SubscriptionID, params.ResourceGroup))
 	}
 
+	// create the checkpoint store
+	state, err := checkpoint.NewStore(params.StateDir, params.Resume, newScanOptions(params, filters))
+	if err != nil {
+		return nil, err
+	}
+
 	// create Azure credentials
-	cred := sc.newAzureCredential(params.ForceAzureCliCredential)
+	cred, err := sc.newAzureCredential(params.ForceAzureCliCredential)
+	if err != nil {
+		return nil, err
+	}
 
 	// create a cancelable context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -88,36 +192,70 @@ func (sc Scanner) Scan(params *ScanParams) {
 				MaxRetries:    3,
 				MaxRetryDelay: 10 * time.Minute,
 			},
+			PerRetryPolicies: []policy.Policy{
+				throttling.NewLimiter(params.MaxConcurrency),
+			},
 		},
 	}
 
+	// resolve the subscriptions of the management group. Key is subscription ID, value is the management group path
+	var managementGroups map[string]string
+	if params.ManagementGroup != "" {
+		managementGroups, err = scanners.ManagementGroupScanner{}.ListSubscriptions(ctx, cred, params.ManagementGroup, clientOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// list subscriptions. Key is subscription ID, value is subscription name
 	subscriptionScanner := scanners.SubcriptionScanner{}
-	subscriptions := subscriptionScanner.ListSubscriptions(ctx, cred, params.SubscriptionID, filters, clientOptions)
+	subscriptions, err := subscriptionScanner.ListSubscriptions(ctx, cred, params.SubscriptionID, managementGroups, filters, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// the management group scope returns the resources of every subscription of the group,
+	// so it is only used when none of them is filtered out
+	graphScope := ""
+	if params.ManagementGroup != "" {
+		if len(subscriptions) == len(managementGroups) {
+			graphScope = params.ManagementGroup
+		} else {
+			log.Debug().Msgf("Some subscriptions of management group %s are filtered out. Resource Graph queries use the subscriptions", params.ManagementGroup)
+		}
+	}
+	aprlScanner.ManagementGroup = graphScope
 
 	// initialize scanners
-	defenderScanner := scanners.DefenderScanner{}
-	pipScanner := scanners.PublicIPScanner{}
-	peScanner := scanners.PrivateEndpointScanner{}
 	diagnosticsScanner := scanners.DiagnosticSettingsScanner{}
-	advisorScanner := scanners.AdvisorScanner{}
-	costScanner := scanners.CostScanner{}
 	diagResults := map[string]bool{}
 
 	// initialize report data
 	reportData := renderers.NewReportData(outputFile, params.Mask)
+	if managementGroups != nil {
+		reportData.ManagementGroups = managementGroups
+	}
 
-	// get the APRL scan results
-	aprlScanner := AprlScanner{}
-	reportData.Recomendations, reportData.AprlData = aprlScanner.Scan(ctx, cred, params.ServiceScanners, filters, subscriptions)
+	// failures of the scan components
+	failures := &scanFailures{failFast: params.FailFast}
 
-	resourceScanner := scanners.ResourceScanner{}
-	reportData.Resources = resourceScanner.GetAllResources(ctx, cred, subscriptions, filters)
+	// the inventory is read first, as the resource selectors of the filters need the tags and location of the resources
+	resourceScanner := scanners.ResourceScanner{ManagementGroup: graphScope}
+	reportData.Resources, err = resourceScanner.GetAllResources(ctx, cred, subscriptions, filters)
+	if err := failures.add("", "", "Resources", err); err != nil {
+		return nil, err
+	}
+
+	// get the APRL scan results
+	reportData.Recomendations, reportData.AprlData, err = aprlScanner.Scan(ctx, cred, aprl, params.ServiceScanners(), filters, subscriptions, state)
+	if err := failures.add("", "", "APRL", err); err != nil {
+		return nil, err
+	}
 
 	// For each service scanner, get the recommendations list
 	if params.UseAzqrRecommendations {
-		for _, s := range params.ServiceScanners {
-			for i, r := range s.GetRecommendations() {
+		for _, s := range params.ServiceScanners() {
+			for i, r := range azqr.GetRecommendations(s) {
 				if filters.Azqr.IsRecommendationExcluded(r.RecommendationID) {
 					continue
 				}
@@ -135,106 +273,387 @@ func (sc Scanner) Scan(params *ScanParams) {
 		}
 
 		// scan diagnostic settings
-		err := diagnosticsScanner.Init(ctx, cred, clientOptions)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to initialize diagnostic settings scanner")
+		if !state.Load("diagnostics", &diagResults) {
+			err := diagnosticsScanner.Init(ctx, cred, clientOptions)
+			if err == nil {
+				diagResults, err = diagnosticsScanner.Scan(reportData.ResourceIDs())
+			}
+			if err := failures.add("", "", "Diagnostic Settings", err); err != nil {
+				return nil, err
+			}
+			if err == nil {
+				state.Save("diagnostics", diagResults)
+			}
 		}
+	}
 
-		diagResults = diagnosticsScanner.Scan(reportData.ResourceIDs())
+	// scan the subscriptions concurrently. The limiter bounds the in-flight ARM requests of all of them
+	run := &scanRun{
+		params:      params,
+		filters:     filters,
+		state:       state,
+		failures:    failures,
+		diagResults: diagResults,
+	}
+
+	numWorkers := params.SubscriptionConcurrency
+	if numWorkers <= 0 || numWorkers > len(subscriptions) {
+		numWorkers = len(subscriptions)
+	}
+
+	jobs := make(chan *azqr.ScannerConfig, len(subscriptions))
+	ch := make(chan subscriptionScanResult, len(subscriptions))
+	for w := 0; w < numWorkers; w++ {
+		go sc.subscriptionWorker(run, jobs, ch)
 	}
 
-	// scan each subscription with AZQR scanners
 	for sid, sn := range subscriptions {
-		config := &azqr.ScannerConfig{
+		jobs <- &azqr.ScannerConfig{
 			Ctx:              ctx,
 			SubscriptionID:   sid,
 			SubscriptionName: sn,
 			Cred:             cred,
 			ClientOptions:    clientOptions,
 		}
+	}
+	close(jobs)
 
-		if params.UseAzqrRecommendations {
-			// scan private endpoints
-			peResults := peScanner.Scan(config)
+	for i := 0; i < len(subscriptions); i++ {
+		res := <-ch
+		if res.err != nil {
+			cancel()
+			return nil, res.err
+		}
 
-			// scan public IPs
-			pips := pipScanner.Scan(config)
+		reportData.AzqrData = append(reportData.AzqrData, res.azqr...)
+		reportData.DefenderData = append(reportData.DefenderData, res.defender...)
+		reportData.AdvisorData = append(reportData.AdvisorData, res.advisor...)
+		if res.costs != nil {
+			reportData.CostData.From = res.costs.From
+			reportData.CostData.To = res.costs.To
+			reportData.CostData.Items = append(reportData.CostData.Items, res.costs.Items...)
+		}
+	}
 
-			// initialize scan context
-			scanContext := azqr.ScanContext{
-				Filters:             filters,
-				PrivateEndpoints:    peResults,
-				DiagnosticsSettings: diagResults,
-				PublicIPs:           pips,
-			}
+	reportData.ResourceTypeCount, err = resourceScanner.GetCountPerResourceType(ctx, cred, subscriptions, reportData.Recomendations)
+	if err := failures.add("", "", "Resource Types", err); err != nil {
+		return nil, err
+	}
+	if reportData.ResourceTypeCount == nil {
+		reportData.ResourceTypeCount = []azqr.ResourceTypeCount{}
+	}
 
-			// scan each resource group
-			ch := make(chan []azqr.AzqrServiceResult, len(params.ServiceScanners))
+	reportData.Errors = failures.errors
 
-			for _, s := range params.ServiceScanners {
-				err := s.Init(config)
-				if err != nil {
-					log.Fatal().Err(err).Msg("Failed to initialize scanner")
-				}
+	// move the suppressed and opted out findings out of the report
+	expired := suppressFindings(&reportData, filters.Azqr, params.IgnoreTag, time.Now().UTC())
+
+	// render snapshot
+	if params.Snapshot {
+		if err := snapshot.CreateSnapshot(&reportData); err != nil {
+			return failures.errors, err
+		}
+	}
+
+	formats := reportFormats{
+		Csv:              params.Csv,
+		Json:             params.Json,
+		Sarif:            params.Sarif,
+		JUnit:            params.JUnit,
+		Html:             params.Html,
+		Markdown:         params.Markdown,
+		MarkdownTemplate: params.MarkdownTemplate,
+		Ndjson:           params.Ndjson,
+		Parquet:          params.Parquet,
+		ExcelProfile:     params.ExcelProfile,
+	}
+	if err := renderReports(&reportData, formats); err != nil {
+		return failures.errors, err
+	}
+
+	// publish posture metrics
+	if exporter != nil {
+		if err := metrics.Export(ctx, exporter, &reportData); err != nil {
+			return failures.errors, err
+		}
+	}
+
+	// notify the new findings
+	if webhook != nil {
+		if err := webhook.Notify(ctx, notify.NewPayload(&reportData, baseline, params.WebhookTop)); err != nil {
+			return failures.errors, err
+		}
+	}
+
+	logSuppressions(&reportData, expired)
+
+	if len(failures.errors) > 0 {
+		log.Warn().Msgf("Scan completed with %d errors. Check the Errors sheet of the report.", len(failures.errors))
+	} else {
+		log.Info().Msg("Scan completed.")
+	}
+
+	if failOnImpact != "" {
+		if count := reportData.CountFindings(failOnImpact); count > 0 {
+			return failures.errors, fmt.Errorf("found %d findings with %s impact or higher", count, failOnImpact)
+		}
+	}
+
+	return failures.errors, nil
+}
 
-				go func(s azqr.IAzureScanner) {
-					res, err := sc.retry(3, 10*time.Millisecond, s, &scanContext)
-					if err != nil {
-						cancel()
-						log.Fatal().Err(err).Msg("Failed to scan")
-					}
-					ch <- res
-				}(s)
+type (
+	// scanOptions holds the options that change the results of the checkpointed units.
+	// A scan is only resumed with the same options.
+	scanOptions struct {
+		SubscriptionID  string   `json:"subscriptionId"`
+		ResourceGroup   string   `json:"resourceGroup"`
+		ManagementGroup string   `json:"managementGroup,omitempty"`
+		Defender        bool     `json:"defender"`
+		Advisor         bool     `json:"advisor"`
+		Cost            bool     `json:"costs"`
+		Azqr            bool     `json:"azqr"`
+		Scanners        []string `json:"scanners"`
+		Filters         string   `json:"filters"`
+		RulesDir        string   `json:"rulesDir"`
+		AprlRulesDir    string   `json:"aprlRulesDir"`
+	}
+
+	// scanRun holds the state shared by the subscription scans
+	scanRun struct {
+		params      *ScanParams
+		filters     *azqr.Filters
+		state       *checkpoint.Store
+		failures    *scanFailures
+		diagResults map[string]bool
+	}
+
+	// subscriptionScanResult holds the results of the scan of a subscription, or the error that aborted it
+	subscriptionScanResult struct {
+		azqr     []azqr.AzqrServiceResult
+		defender []scanners.DefenderResult
+		advisor  []scanners.AdvisorResult
+		costs    *scanners.CostResult
+		err      error
+	}
+
+	// serviceScanResult holds the results of a service scanner for a subscription
+	serviceScanResult struct {
+		scanner azqr.IAzureScanner
+		results []azqr.AzqrServiceResult
+		err     error
+	}
+
+	// scanFailures collects the failures of the scan components
+	scanFailures struct {
+		mu       sync.Mutex
+		failFast bool
+		errors   []azqr.ScanError
+	}
+)
+
+func (sc Scanner) subscriptionWorker(run *scanRun, jobs <-chan *azqr.ScannerConfig, results chan<- subscriptionScanResult) {
+	for config := range jobs {
+		results <- sc.scanSubscription(run, config)
+	}
+}
+
+// scanSubscription scans a subscription with the AZQR, Defender, Advisor and Cost scanners.
+// Scanners keep per subscription state, so each subscription creates its own instances.
+func (sc Scanner) scanSubscription(run *scanRun, config *azqr.ScannerConfig) subscriptionScanResult {
+	sid, sn := config.SubscriptionID, config.SubscriptionName
+	params, filters, state, failures := run.params, run.filters, run.state, run.failures
+	result := subscriptionScanResult{}
+
+	// skip the service scanners completed by a previous run
+	pending := []azqr.IAzureScanner{}
+	if params.UseAzqrRecommendations {
+		for _, s := range params.ServiceScanners() {
+			completed := []azqr.AzqrServiceResult{}
+			if state.Load(serviceUnit(sid, s), &completed) {
+				result.azqr = appendServiceResults(result.azqr, completed, filters)
+				continue
 			}
+			pending = append(pending, s)
+		}
+	}
 
-			for i := 0; i < len(params.ServiceScanners); i++ {
-				res := <-ch
-				for _, r := range res {
-					// check if the resource is excluded
-					if filters.Azqr.IsServiceExcluded(r.ResourceID()) {
-						continue
-					}
-					reportData.AzqrData = append(reportData.AzqrData, r)
-				}
+	if len(pending) > 0 {
+		peScanner := scanners.PrivateEndpointScanner{}
+		pipScanner := scanners.PublicIPScanner{}
+
+		// scan private endpoints
+		peResults, peErr := peScanner.Scan(config)
+		if err := failures.add(sid, sn, "Private Endpoints", peErr); err != nil {
+			return subscriptionScanResult{err: err}
+		}
+
+		// scan public IPs
+		pips, pipErr := pipScanner.Scan(config)
+		if err := failures.add(sid, sn, "Public IPs", pipErr); err != nil {
+			return subscriptionScanResult{err: err}
+		}
+
+		// initialize scan context
+		scanContext := azqr.ScanContext{
+			Filters:             filters,
+			PrivateEndpoints:    peResults,
+			DiagnosticsSettings: run.diagResults,
+			PublicIPs:           pips,
+		}
+
+		// scan each resource group
+		ch := sc.scanServices(config, pending, &scanContext)
+
+		for i := 0; i < len(pending); i++ {
+			res := <-ch
+			if err := failures.add(sid, sn, strings.Join(res.scanner.ResourceTypes(), ", "), res.err); err != nil {
+				return subscriptionScanResult{err: err}
+			}
+			// results evaluated without private endpoints or public IPs are scanned again on resume
+			if res.err == nil && peErr == nil && pipErr == nil {
+				state.Save(serviceUnit(sid, res.scanner), res.results)
 			}
+			result.azqr = appendServiceResults(result.azqr, res.results, filters)
 		}
+	}
 
-		// scan defender
-		reportData.DefenderData = append(reportData.DefenderData, defenderScanner.Scan(params.Defender, config)...)
+	var err error
 
-		// scan advisor
-		reportData.AdvisorData = append(reportData.AdvisorData, advisorScanner.Scan(params.Advisor, config)...)
+	// scan defender
+	if !state.Load(subscriptionUnit(sid, "defender"), &result.defender) {
+		defenderScanner := scanners.DefenderScanner{}
+		result.defender, err = defenderScanner.Scan(params.Defender, config)
+		if err := failures.add(sid, sn, "Defender", err); err != nil {
+			return subscriptionScanResult{err: err}
+		}
+		if err == nil {
+			state.Save(subscriptionUnit(sid, "defender"), result.defender)
+		}
+	}
+
+	// scan advisor
+	if !state.Load(subscriptionUnit(sid, "advisor"), &result.advisor) {
+		advisorScanner := scanners.AdvisorScanner{}
+		result.advisor, err = advisorScanner.Scan(params.Advisor, config)
+		if err := failures.add(sid, sn, "Advisor", err); err != nil {
+			return subscriptionScanResult{err: err}
+		}
+		if err == nil {
+			state.Save(subscriptionUnit(sid, "advisor"), result.advisor)
+		}
+	}
+
+	// scan costs
+	if !state.Load(subscriptionUnit(sid, "costs"), &result.costs) {
+		costScanner := scanners.CostScanner{}
+		result.costs, err = costScanner.Scan(params.Cost, config)
+		if err := failures.add(sid, sn, "Costs", err); err != nil {
+			return subscriptionScanResult{err: err}
+		}
+		if err == nil {
+			state.Save(subscriptionUnit(sid, "costs"), result.costs)
+		}
+	}
+
+	return result
+}
+
+// add records the failure of a component. If fail fast is enabled the failure is returned, so the scan can be aborted.
+func (f *scanFailures) add(subscriptionID, subscriptionName, component string, err error) error {
+	if err == nil {
+		return nil
+	}
 
-		// scan costs
-		costs := costScanner.Scan(params.Cost, config)
-		reportData.CostData.From = costs.From
-		reportData.CostData.To = costs.To
-		reportData.CostData.Items = append(reportData.CostData.Items, costs.Items...)
+	scanError := azqr.NewScanError(subscriptionID, subscriptionName, component, err)
+	if f.failFast {
+		return scanError
 	}
 
-	reportData.ResourceTypeCount = resourceScanner.GetCountPerResourceType(ctx, cred, subscriptions, reportData.Recomendations)
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
-	// render excel report
-	excel.CreateExcelReport(&reportData)
+	log.Error().Err(err).Msgf("%s scan failed. Continuing with the scan...", component)
+	f.errors = append(f.errors, *scanError)
+	return nil
+}
+
+// scanServices runs the service scanners concurrently and sends their results to the returned channel.
+// Each scanner gets its own copy of the scan context, so resource specific data set by a scanner is never seen by another.
+func (sc Scanner) scanServices(config *azqr.ScannerConfig, serviceScanners []azqr.IAzureScanner, scanContext *azqr.ScanContext) <-chan serviceScanResult {
+	ch := make(chan serviceScanResult, len(serviceScanners))
+
+	for _, s := range serviceScanners {
+		err := s.Init(config)
+		if err != nil {
+			ch <- serviceScanResult{scanner: s, err: err}
+			continue
+		}
+
+		go func(s azqr.IAzureScanner, scanContext *azqr.ScanContext) {
+			res, err := sc.retry(3, 10*time.Millisecond, s, scanContext)
+			ch <- serviceScanResult{scanner: s, results: res, err: err}
+		}(s, scanContext.ForResource())
+	}
+
+	return ch
+}
 
-	// render json report
-	if params.Json {
-		json.CreateJsonReport(&reportData)
+// appendServiceResults appends the results of a service scanner, skipping the excluded resources
+func appendServiceResults(data []azqr.AzqrServiceResult, results []azqr.AzqrServiceResult, filters *azqr.Filters) []azqr.AzqrServiceResult {
+	for _, r := range results {
+		// check if the resource is excluded
+		if filters.Azqr.IsServiceExcluded(r.ResourceID()) {
+			continue
+		}
+		data = append(data, r)
 	}
+	return data
+}
 
-	// render csv reports
-	if params.Csv {
-		csv.CreateCsvReport(&reportData)
+// newScanOptions returns the options of a scan to be saved with its checkpoints
+func newScanOptions(params *ScanParams, filters *azqr.Filters) scanOptions {
+	options := scanOptions{
+		SubscriptionID:  params.SubscriptionID,
+		ResourceGroup:   params.ResourceGroup,
+		ManagementGroup: params.ManagementGroup,
+		Defender:        params.Defender,
+		Advisor:         params.Advisor,
+		Cost:            params.Cost,
+		Azqr:            params.UseAzqrRecommendations,
+		Scanners:        []string{},
+		RulesDir:        params.RulesDir,
+		AprlRulesDir:    params.AprlRulesDir,
 	}
 
-	log.Info().Msg("Scan completed.")
+	for _, s := range params.ServiceScanners() {
+		options.Scanners = append(options.Scanners, s.ResourceTypes()[0])
+	}
+	sort.Strings(options.Scanners)
+
+	// the filters are compared by content, as the file can change between runs
+	if js, err := json.Marshal(filters); err == nil {
+		options.Filters = checkpoint.Key(string(js))
+	}
+	return options
+}
+
+// subscriptionUnit returns the checkpoint unit of a subscription scan component
+func subscriptionUnit(subscriptionID, component string) string {
+	return fmt.Sprintf("subscriptions/%s/%s", subscriptionID, component)
+}
+
+// serviceUnit returns the checkpoint unit of a service scanner for a subscription
+func serviceUnit(subscriptionID string, s azqr.IAzureScanner) string {
+	return subscriptionUnit(subscriptionID, "azqr/"+checkpoint.UnitName(s.ResourceTypes()[0]))
 }
 
 // retry retries the Azure scanner Scan, a number of times with an increasing delay between retries
 func (sc Scanner) retry(attempts int, sleep time.Duration, a azqr.IAzureScanner, scanContext *azqr.ScanContext) ([]azqr.AzqrServiceResult, error) {
 	var err error
 	for i := 0; ; i++ {
-		res, err := a.Scan(scanContext)
+		var res []azqr.AzqrServiceResult
+		res, err = a.Scan(scanContext)
 		if err == nil {
 			return res, nil
 		}
@@ -258,24 +677,24 @@ func (sc Scanner) retry(attempts int, sleep time.Duration, a azqr.IAzureScanner,
 	return nil, err
 }
 
-func (sc Scanner) newAzureCredential(forceAzureCliCredential bool) azcore.TokenCredential {
+func (sc Scanner) newAzureCredential(forceAzureCliCredential bool) (azcore.TokenCredential, error) {
 	var cred azcore.TokenCredential
 	var err error
 	if !forceAzureCliCredential {
 		cred, err = azidentity.NewDefaultAzureCredential(nil)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to get Azure credentials")
+			return nil, fmt.Errorf("failed to get Azure credentials: %w", err)
 		}
 	} else {
 		cred, err = azidentity.NewAzureCLICredential(nil)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to get Azure CLI credentials")
+			return nil, fmt.Errorf("failed to get Azure CLI credentials: %w", err)
 		}
 	}
-	return cred
+	return cred, nil
 }
 
-func (sc Scanner) generateOutputFileName(outputName string) string {
+func generateOutputFileName(outputName string) string {
 	outputFile := outputName
 	if outputFile == "" {
 		current_time := time.Now()
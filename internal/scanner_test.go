@@ -0,0 +1,283 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/checkpoint"
+	"github.com/Azure/azqr/internal/scanners/asp"
+	"github.com/Azure/azqr/internal/scanners/st"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+var (
+	sitesPath      = regexp.MustCompile(`/serverfarms/plan/sites$`)
+	siteConfigPath = regexp.MustCompile(`/sites/(site-\d+-\d+)/config/web$`)
+	blobPath       = regexp.MustCompile(`/storageAccounts/(st\d+x\d+)/blobServices/default$`)
+)
+
+// fakeARMTransport answers the App Service and Storage requests of a subscription with the given number of
+// sites and storage accounts. Every other list request returns no resources.
+// A site or storage account is compliant when the sum of its indexes is even, so a rule evaluated against
+// the data of another resource gives the wrong result.
+// It doesn't synchronize the requests, so the race detector sees the accesses of the scanners as concurrent.
+type fakeARMTransport struct {
+	resources int
+}
+
+func (f *fakeARMTransport) Do(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	sid := strings.Split(path, "/")[2]
+	rg := fmt.Sprintf("/subscriptions/%s/resourceGroups/rg", sid)
+	subIndex := subscriptionIndex(sid)
+
+	var body interface{} = map[string]interface{}{"value": []interface{}{}}
+	switch {
+	case strings.HasSuffix(path, "/providers/Microsoft.Web/serverfarms"):
+		body = map[string]interface{}{"value": []interface{}{
+			map[string]interface{}{"id": rg + "/providers/Microsoft.Web/serverfarms/plan", "name": "plan", "type": "Microsoft.Web/serverfarms", "location": "westeurope",
+				"sku": map[string]interface{}{"name": "P1v3", "tier": "PremiumV3"}},
+		}}
+	case sitesPath.MatchString(path):
+		sites := []interface{}{}
+		for i := 0; i < f.resources; i++ {
+			name := fmt.Sprintf("site-%d-%d", subIndex, i)
+			sites = append(sites, map[string]interface{}{
+				"id": rg + "/providers/Microsoft.Web/sites/" + name, "name": name, "type": "Microsoft.Web/sites", "kind": "app", "location": "westeurope",
+				"properties": map[string]interface{}{"resourceGroup": "rg", "httpsOnly": true},
+			})
+		}
+		body = map[string]interface{}{"value": sites}
+	case siteConfigPath.MatchString(path):
+		tls := "1.0"
+		if compliant(siteConfigPath.FindStringSubmatch(path)[1]) {
+			tls = "1.2"
+		}
+		body = map[string]interface{}{"name": "web", "properties": map[string]interface{}{"minTlsVersion": tls}}
+	case strings.HasSuffix(path, "/providers/Microsoft.Storage/storageAccounts"):
+		accounts := []interface{}{}
+		for i := 0; i < f.resources; i++ {
+			name := fmt.Sprintf("st%dx%d", subIndex, i)
+			accounts = append(accounts, map[string]interface{}{
+				"id": rg + "/providers/Microsoft.Storage/storageAccounts/" + name, "name": name, "type": "Microsoft.Storage/storageAccounts", "location": "westeurope",
+				"sku":        map[string]interface{}{"name": "Standard_LRS", "tier": "Standard"},
+				"properties": map[string]interface{}{"supportsHttpsTrafficOnly": true},
+			})
+		}
+		body = map[string]interface{}{"value": accounts}
+	case blobPath.MatchString(path):
+		enabled := compliant(blobPath.FindStringSubmatch(path)[1])
+		body = map[string]interface{}{"properties": map[string]interface{}{"containerDeleteRetentionPolicy": map[string]interface{}{"enabled": enabled}}}
+	}
+
+	js, _ := json.Marshal(body)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(js))),
+		Request:    req,
+	}, nil
+}
+
+func subscriptionID(i int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", i)
+}
+
+func subscriptionIndex(sid string) int {
+	i, _ := strconv.Atoi(sid[len(sid)-12:])
+	return i
+}
+
+// compliant returns if a resource named after its subscription and resource indexes is compliant
+func compliant(name string) bool {
+	sum := 0
+	for _, n := range regexp.MustCompile(`\d+`).FindAllString(name, -1) {
+		i, _ := strconv.Atoi(n)
+		sum += i
+	}
+	return sum%2 == 0
+}
+
+func newTestRun(t *testing.T, state *checkpoint.Store) *scanRun {
+	t.Helper()
+	filters, err := azqr.LoadFilters("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &scanRun{
+		params: &ScanParams{
+			UseAzqrRecommendations: true,
+			ServiceScanners: func() []azqr.IAzureScanner {
+				return []azqr.IAzureScanner{&asp.AppServiceScanner{}, &st.StorageScanner{}}
+			},
+		},
+		filters:     filters,
+		state:       state,
+		failures:    &scanFailures{},
+		diagResults: map[string]bool{},
+	}
+}
+
+func newTestConfig(sid string, transport policy.Transporter) *azqr.ScannerConfig {
+	return &azqr.ScannerConfig{
+		Ctx:              context.Background(),
+		Cred:             fakeCredential{},
+		SubscriptionID:   sid,
+		SubscriptionName: sid,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: policy.ClientOptions{Transport: transport},
+		},
+	}
+}
+
+// TestScanner_scanSubscription scans several subscriptions concurrently with the App Service and Storage scanners
+// and checks that every rule is evaluated against the data of its own resource. Run it with -race.
+func TestScanner_scanSubscription(t *testing.T) {
+	const subscriptions, resources = 4, 20
+
+	transport := &fakeARMTransport{resources: resources}
+	run := newTestRun(t, &checkpoint.Store{})
+
+	jobs := make(chan *azqr.ScannerConfig, subscriptions)
+	ch := make(chan subscriptionScanResult, subscriptions)
+	sc := Scanner{}
+	for w := 0; w < subscriptions; w++ {
+		go sc.subscriptionWorker(run, jobs, ch)
+	}
+	for i := 0; i < subscriptions; i++ {
+		jobs <- newTestConfig(subscriptionID(i), transport)
+	}
+	close(jobs)
+
+	checked := 0
+	for i := 0; i < subscriptions; i++ {
+		res := <-ch
+		if res.err != nil {
+			t.Fatalf("Scanner.scanSubscription() error = %v", res.err)
+		}
+
+		for _, r := range res.azqr {
+			rule := ""
+			switch r.Type {
+			case "Microsoft.Web/sites":
+				rule = "app-011"
+			case "Microsoft.Storage/storageAccounts":
+				rule = "st-011"
+			default:
+				continue
+			}
+			checked++
+			if got := r.Recommendations[rule].NotCompliant; got == compliant(r.ServiceName) {
+				t.Errorf("Scanner.scanSubscription() %s %s not compliant = %v, want %v", r.ServiceName, rule, got, !compliant(r.ServiceName))
+			}
+		}
+	}
+
+	if len(run.failures.errors) > 0 {
+		t.Errorf("Scanner.scanSubscription() failures = %v", run.failures.errors)
+	}
+	if want := subscriptions * resources * 2; checked != want {
+		t.Errorf("Scanner.scanSubscription() checked %d resources, want %d", checked, want)
+	}
+}
+
+// recordingTransport records the paths of the requests sent to the fake ARM transport
+type recordingTransport struct {
+	fakeARMTransport
+	mu    sync.Mutex
+	paths []string
+}
+
+func (r *recordingTransport) Do(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.Path)
+	r.mu.Unlock()
+	return r.fakeARMTransport.Do(req)
+}
+
+func TestScanner_scanSubscription_Resume(t *testing.T) {
+	sid := subscriptionID(1)
+	dir := t.TempDir()
+	options := scanOptions{Azqr: true}
+
+	// a previous run completed the App Service scanner
+	previous, err := checkpoint.NewStore(dir, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous.Save(serviceUnit(sid, &asp.AppServiceScanner{}), []azqr.AzqrServiceResult{
+		{SubscriptionID: sid, ResourceGroup: "rg", Type: "Microsoft.Web/sites", ServiceName: "from-checkpoint"},
+	})
+
+	state, err := checkpoint.NewStore(dir, true, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &recordingTransport{fakeARMTransport: fakeARMTransport{resources: 3}}
+	res := Scanner{}.scanSubscription(newTestRun(t, state), newTestConfig(sid, transport))
+	if res.err != nil {
+		t.Fatalf("Scanner.scanSubscription() error = %v", res.err)
+	}
+
+	names := []string{}
+	for _, r := range res.azqr {
+		names = append(names, r.ServiceName)
+	}
+	if want := "from-checkpoint,st1x0,st1x1,st1x2"; strings.Join(names, ",") != want {
+		t.Errorf("Scanner.scanSubscription() = %v, want %v", names, want)
+	}
+
+	for _, p := range transport.paths {
+		if strings.Contains(p, "Microsoft.Web") {
+			t.Errorf("Scanner.scanSubscription() scanned the completed unit again: %s", p)
+		}
+	}
+
+	// the storage scanner is checkpointed for the next resume
+	completed := []azqr.AzqrServiceResult{}
+	if !state.Load(serviceUnit(sid, &st.StorageScanner{}), &completed) || len(completed) != 3 {
+		t.Errorf("Scanner.scanSubscription() didn't checkpoint the storage scanner: %v", completed)
+	}
+}
+
+func Test_newScanOptions(t *testing.T) {
+	filters, _ := azqr.LoadFilters("")
+	params := &ScanParams{
+		Defender: true,
+		ServiceScanners: func() []azqr.IAzureScanner {
+			return []azqr.IAzureScanner{&st.StorageScanner{}, &asp.AppServiceScanner{}}
+		},
+	}
+
+	got := newScanOptions(params, filters)
+	if want := []string{"Microsoft.Storage/storageAccounts", "Microsoft.Web/serverFarms"}; strings.Join(got.Scanners, ",") != strings.Join(want, ",") {
+		t.Errorf("newScanOptions() scanners = %v, want %v", got.Scanners, want)
+	}
+
+	filters.Azqr.AddSubscription("s1")
+	if other := newScanOptions(params, filters); other.Filters == got.Filters {
+		t.Errorf("newScanOptions() didn't change with the filters")
+	}
+}
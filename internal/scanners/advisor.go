@@ -4,9 +4,10 @@
 package scanners
 
 import (
+	"fmt"
+
 	"github.com/Azure/azqr/internal/azqr"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
-	"github.com/rs/zerolog/log"
 )
 
 // AdvisorResult - Advisor result
@@ -81,12 +82,12 @@ func (s *AdvisorScanner) ListRecommendations() ([]AdvisorResult, error) {
 	return returnRecommendations, nil
 }
 
-func (s *AdvisorScanner) Scan(scan bool, config *azqr.ScannerConfig) []AdvisorResult {
+func (s *AdvisorScanner) Scan(scan bool, config *azqr.ScannerConfig) ([]AdvisorResult, error) {
 	advisorResults := []AdvisorResult{}
 	if scan {
 		err := s.Init(config)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to initialize Advisor Scanner")
+			return nil, fmt.Errorf("failed to initialize Advisor Scanner: %w", err)
 		}
 
 		rec, err := s.ListRecommendations()
@@ -94,10 +95,10 @@ func (s *AdvisorScanner) Scan(scan bool, config *azqr.ScannerConfig) []AdvisorRe
 			if azqr.ShouldSkipError(err) {
 				rec = []AdvisorResult{}
 			} else {
-				log.Fatal().Err(err).Msg("Failed to list Advisor recommendations")
+				return nil, fmt.Errorf("failed to list Advisor recommendations: %w", err)
 			}
 		}
 		advisorResults = append(advisorResults, rec...)
 	}
-	return advisorResults
+	return advisorResults, nil
 }
@@ -72,14 +72,15 @@ func (a *AppServiceScanner) Scan(scanContext *azqr.ScanContext) ([]azqr.AzqrServ
 			if err != nil {
 				return nil, err
 			}
-			scanContext.SiteConfig = &config
+			siteContext := scanContext.ForResource()
+			siteContext.SiteConfig = &config
 
 			var result azqr.AzqrServiceResult
 			// https://learn.microsoft.com/en-us/azure/azure-functions/functions-app-settings
 			kind := strings.ToLower(*s.Kind)
 			switch kind {
 			case "functionapp,linux", "functionapp":
-				rr := engine.EvaluateRecommendations(functionRules, s, scanContext)
+				rr := engine.EvaluateRecommendations(functionRules, s, siteContext)
 
 				result = azqr.AzqrServiceResult{
 					SubscriptionID:   a.config.SubscriptionID,
@@ -91,7 +92,7 @@ func (a *AppServiceScanner) Scan(scanContext *azqr.ScanContext) ([]azqr.AzqrServ
 					Recommendations:  rr,
 				}
 			case "functionapp,workflowapp":
-				rr := engine.EvaluateRecommendations(logicRules, s, scanContext)
+				rr := engine.EvaluateRecommendations(logicRules, s, siteContext)
 
 				result = azqr.AzqrServiceResult{
 					SubscriptionID:   a.config.SubscriptionID,
@@ -103,7 +104,7 @@ func (a *AppServiceScanner) Scan(scanContext *azqr.ScanContext) ([]azqr.AzqrServ
 					Recommendations:  rr,
 				}
 			default:
-				rr := engine.EvaluateRecommendations(appRules, s, scanContext)
+				rr := engine.EvaluateRecommendations(appRules, s, siteContext)
 				result = azqr.AzqrServiceResult{
 					SubscriptionID:   a.config.SubscriptionID,
 					SubscriptionName: a.config.SubscriptionName,
@@ -72,12 +72,12 @@ func (s *DefenderScanner) ListConfiguration() ([]DefenderResult, error) {
 	return s.defenderFunc()
 }
 
-func (s *DefenderScanner) Scan(scan bool, config *azqr.ScannerConfig) []DefenderResult {
+func (s *DefenderScanner) Scan(scan bool, config *azqr.ScannerConfig) ([]DefenderResult, error) {
 	defenderResults := []DefenderResult{}
 	if scan {
 		err := s.Init(config)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to initialize Defender Scanner")
+			return nil, fmt.Errorf("failed to initialize Defender Scanner: %w", err)
 		}
 
 		res, err := s.ListConfiguration()
@@ -85,10 +85,10 @@ func (s *DefenderScanner) Scan(scan bool, config *azqr.ScannerConfig) []Defender
 			if azqr.ShouldSkipError(err) {
 				res = []DefenderResult{}
 			} else {
-				log.Fatal().Err(err).Msg("Failed to list Defender configuration")
+				return nil, fmt.Errorf("failed to list Defender configuration: %w", err)
 			}
 		}
 		defenderResults = append(defenderResults, res...)
 	}
-	return defenderResults
+	return defenderResults, nil
 }
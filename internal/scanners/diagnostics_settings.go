@@ -5,6 +5,7 @@ package scanners
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -55,7 +56,7 @@ func (d *DiagnosticSettingsScanner) ListResourcesWithDiagnosticSettings(resource
 
 	log.Debug().Msgf("Number of diagnostic setting batches: %d", batches)
 	jobs := make(chan []*string, batches)
-	ch := make(chan map[string]bool, batches)
+	ch := make(chan diagnosticSettingsBatchResult, batches)
 	var wg sync.WaitGroup
 
 	// Start workers
@@ -91,29 +92,42 @@ func (d *DiagnosticSettingsScanner) ListResourcesWithDiagnosticSettings(resource
 	close(jobs)
 	wg.Wait()
 
+	errs := []error{}
 	for i := 0; i < batches; i++ {
-		for k, v := range <-ch {
+		batch := <-ch
+		if batch.err != nil {
+			errs = append(errs, batch.err)
+		}
+		for k, v := range batch.results {
 			res[k] = v
 		}
 	}
 
-	return res, nil
+	return res, errors.Join(errs...)
+}
+
+// diagnosticSettingsBatchResult holds the resources with diagnostic settings found in a batch, or the error of the batch
+type diagnosticSettingsBatchResult struct {
+	results map[string]bool
+	err     error
 }
 
-func (d *DiagnosticSettingsScanner) worker(jobs <-chan []*string, results chan<- map[string]bool, wg *sync.WaitGroup) {
+func (d *DiagnosticSettingsScanner) worker(jobs <-chan []*string, results chan<- diagnosticSettingsBatchResult, wg *sync.WaitGroup) {
 	for ids := range jobs {
+		asyncRes := map[string]bool{}
 		resp, err := d.restCall(d.ctx, ids)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to get diagnostic settings")
+			results <- diagnosticSettingsBatchResult{results: asyncRes, err: fmt.Errorf("failed to get diagnostic settings: %w", err)}
+			wg.Done()
+			continue
 		}
-		asyncRes := map[string]bool{}
 		for _, response := range resp.Responses {
 			for _, diagnosticSetting := range response.Content.Value {
 				id := parseResourceId(diagnosticSetting.ID)
 				asyncRes[id] = true
 			}
 		}
-		results <- asyncRes
+		results <- diagnosticSettingsBatchResult{results: asyncRes}
 		wg.Done()
 	}
 }
@@ -191,14 +205,14 @@ type (
 	}
 )
 
-func (d *DiagnosticSettingsScanner) Scan(resources []*string) map[string]bool {
+// Scan - Returns the resources with diagnostic settings. If some batches fail, the resources found in the other batches are returned along with the error.
+func (d *DiagnosticSettingsScanner) Scan(resources []*string) (map[string]bool, error) {
 	diagResults, err := d.ListResourcesWithDiagnosticSettings(resources)
 	if err != nil {
 		if azqr.ShouldSkipError(err) {
-			diagResults = map[string]bool{}
-		} else {
-			log.Fatal().Err(err).Msg("Failed to list resources with Diagnostic Settings")
+			return map[string]bool{}, nil
 		}
+		return diagResults, fmt.Errorf("failed to list resources with Diagnostic Settings: %w", err)
 	}
-	return diagResults
+	return diagResults, nil
 }
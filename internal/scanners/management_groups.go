@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/rs/zerolog/log"
+)
+
+const subscriptionDescendantType = "/subscriptions"
+
+// ManagementGroupScanner - Resolves the subscriptions of a management group
+type ManagementGroupScanner struct{}
+
+// ListSubscriptions - Returns the descendant subscriptions of the management group. Key is subscription ID,
+// value is the path of management groups from the given one to the subscription, e.g. Corp/Online
+func (sc ManagementGroupScanner) ListSubscriptions(ctx context.Context, cred azcore.TokenCredential, managementGroupID string, options *arm.ClientOptions) (map[string]string, error) {
+	client, err := armmanagementgroups.NewClient(cred, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create management groups client: %w", err)
+	}
+
+	descendants := []*armmanagementgroups.DescendantInfo{}
+	pager := client.NewGetDescendantsPager(managementGroupID, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list descendants of management group %s: %w", managementGroupID, err)
+		}
+		descendants = append(descendants, page.Value...)
+	}
+
+	result := managementGroupPaths(managementGroupID, descendants)
+	log.Info().Msgf("Management group %s has %d subscriptions", managementGroupID, len(result))
+	return result, nil
+}
+
+// managementGroupPaths returns the path of management groups of each descendant subscription
+func managementGroupPaths(managementGroupID string, descendants []*armmanagementgroups.DescendantInfo) map[string]string {
+	parents := map[string]string{}
+	for _, d := range descendants {
+		if d.Name == nil || d.Properties == nil || d.Properties.Parent == nil || d.Properties.Parent.ID == nil {
+			continue
+		}
+		id := *d.Properties.Parent.ID
+		parents[strings.ToLower(*d.Name)] = id[strings.LastIndex(id, "/")+1:]
+	}
+
+	path := func(group string) string {
+		names := []string{}
+		// the depth of a management group hierarchy is limited to 6 levels, the guard protects from malformed responses
+		for i := 0; i <= len(parents) && !strings.EqualFold(group, managementGroupID); i++ {
+			names = append([]string{group}, names...)
+			parent, ok := parents[strings.ToLower(group)]
+			if !ok {
+				break
+			}
+			group = parent
+		}
+		return strings.Join(append([]string{managementGroupID}, names...), "/")
+	}
+
+	result := map[string]string{}
+	for _, d := range descendants {
+		if d.Type == nil || !strings.EqualFold(*d.Type, subscriptionDescendantType) || d.Name == nil {
+			continue
+		}
+		parent := managementGroupID
+		if p, ok := parents[strings.ToLower(*d.Name)]; ok {
+			parent = p
+		}
+		result[strings.ToLower(*d.Name)] = path(parent)
+	}
+	return result
+}
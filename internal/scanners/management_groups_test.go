@@ -0,0 +1,44 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package scanners
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azqr/internal/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+)
+
+func descendant(name, descendantType, parent string) *armmanagementgroups.DescendantInfo {
+	return &armmanagementgroups.DescendantInfo{
+		Name: to.Ptr(name),
+		Type: to.Ptr(descendantType),
+		Properties: &armmanagementgroups.DescendantInfoProperties{
+			Parent: &armmanagementgroups.DescendantParentGroupInfo{
+				ID: to.Ptr("/providers/Microsoft.Management/managementGroups/" + parent),
+			},
+		},
+	}
+}
+
+func Test_managementGroupPaths(t *testing.T) {
+	group := "Microsoft.Management/managementGroups"
+	descendants := []*armmanagementgroups.DescendantInfo{
+		descendant("Online", group, "Corp"),
+		descendant("Prod", group, "Online"),
+		descendant("00000000-0000-0000-0000-000000000001", subscriptionDescendantType, "Corp"),
+		descendant("00000000-0000-0000-0000-000000000002", subscriptionDescendantType, "Online"),
+		descendant("00000000-0000-0000-0000-00000000000A", subscriptionDescendantType, "Prod"),
+	}
+
+	want := map[string]string{
+		"00000000-0000-0000-0000-000000000001": "Corp",
+		"00000000-0000-0000-0000-000000000002": "Corp/Online",
+		"00000000-0000-0000-0000-00000000000a": "Corp/Online/Prod",
+	}
+	if got := managementGroupPaths("Corp", descendants); !reflect.DeepEqual(got, want) {
+		t.Errorf("managementGroupPaths() = %v, want %v", got, want)
+	}
+}
@@ -4,9 +4,10 @@
 package scanners
 
 import (
+	"fmt"
+
 	"github.com/Azure/azqr/internal/azqr"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
-	"github.com/rs/zerolog/log"
 )
 
 // PrivateEndpointScanner - Scanner for Private Endpoints
@@ -58,18 +59,18 @@ func (s *PrivateEndpointScanner) ListResourcesWithPrivateEndpoints() (map[string
 	return s.hasPrivateEndpointFunc()
 }
 
-func (s *PrivateEndpointScanner) Scan(config *azqr.ScannerConfig) map[string]bool {
+func (s *PrivateEndpointScanner) Scan(config *azqr.ScannerConfig) (map[string]bool, error) {
 	err := s.Init(config)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to initialize Private Endpoint Scanner")
+		return nil, fmt.Errorf("failed to initialize Private Endpoint Scanner: %w", err)
 	}
 	peResults, err := s.ListResourcesWithPrivateEndpoints()
 	if err != nil {
 		if azqr.ShouldSkipError(err) {
 			peResults = map[string]bool{}
 		} else {
-			log.Fatal().Err(err).Msg("Failed to list resources with Private Endpoints")
+			return nil, fmt.Errorf("failed to list resources with Private Endpoints: %w", err)
 		}
 	}
-	return peResults
+	return peResults, nil
 }
@@ -4,9 +4,10 @@
 package scanners
 
 import (
+	"fmt"
+
 	"github.com/Azure/azqr/internal/azqr"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
-	"github.com/rs/zerolog/log"
 )
 
 // PublicIPScanner - Scanner for Public IPs
@@ -49,18 +50,18 @@ func (s *PublicIPScanner) ListPublicIPs() (map[string]*armnetwork.PublicIPAddres
 	return res, nil
 }
 
-func (s *PublicIPScanner) Scan(config *azqr.ScannerConfig) map[string]*armnetwork.PublicIPAddress {
+func (s *PublicIPScanner) Scan(config *azqr.ScannerConfig) (map[string]*armnetwork.PublicIPAddress, error) {
 	err := s.Init(config)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to initialize Diagnostic Settings Scanner")
+		return nil, fmt.Errorf("failed to initialize Public IP Scanner: %w", err)
 	}
 	pips, err := s.ListPublicIPs()
 	if err != nil {
 		if azqr.ShouldSkipError(err) {
 			pips = map[string]*armnetwork.PublicIPAddress{}
 		} else {
-			log.Fatal().Err(err).Msg("Failed to list Public IPs")
+			return nil, fmt.Errorf("failed to list Public IPs: %w", err)
 		}
 	}
-	return pips
+	return pips, nil
 }
@@ -10,28 +10,34 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type ResourceScanner struct{}
+type ResourceScanner struct {
+	// ManagementGroup runs the queries at the scope of the management group instead of batches of subscriptions
+	ManagementGroup string
+}
 
-func (sc ResourceScanner) GetAllResources(ctx context.Context, cred azcore.TokenCredential, subscriptions map[string]string, filters *azqr.Filters) []*azqr.Resource {
+func (sc ResourceScanner) GetAllResources(ctx context.Context, cred azcore.TokenCredential, subscriptions map[string]string, filters *azqr.Filters) ([]*azqr.Resource, error) {
 	azqr.LogResourceTypeScan("Resources")
 
-	graphClient := graph.NewGraphQuery(cred)
-	query := "resources | project id, subscriptionId, resourceGroup, location, type, name, sku.name, sku.tier, kind"
+	graphClient, err := graph.NewGraphQuery(cred)
+	if err != nil {
+		return nil, err
+	}
+	graphClient.ScopeToManagementGroup(sc.ManagementGroup)
+	query := "resources | project id, subscriptionId, resourceGroup, location, type, name, sku.name, sku.tier, kind, tags"
 	log.Debug().Msg(query)
 	subs := make([]*string, 0, len(subscriptions))
 	for s := range subscriptions {
 		subs = append(subs, &s)
 	}
-	result := graphClient.Query(ctx, query, subs)
+	result, err := graphClient.Query(ctx, query, subs)
+	if err != nil {
+		return nil, err
+	}
 	resources := []*azqr.Resource{}
 	if result.Data != nil {
 		for _, row := range result.Data {
 			m := row.(map[string]interface{})
 
-			if filters.Azqr.IsServiceExcluded(m["id"].(string)) {
-				continue
-			}
-
 			skuName := ""
 			if m["sku_name"] != nil {
 				skuName = m["sku_name"].(string)
@@ -57,34 +63,57 @@ func (sc ResourceScanner) GetAllResources(ctx context.Context, cred azcore.Token
 				location = m["location"].(string)
 			}
 
-			resources = append(
-				resources,
-				&azqr.Resource{
-					ID:             m["id"].(string),
-					SubscriptionID: m["subscriptionId"].(string),
-					ResourceGroup:  resourceGroup,
-					Location:       location,
-					Type:           m["type"].(string),
-					Name:           m["name"].(string),
-					SkuName:        skuName,
-					SkuTier:        skuTier,
-					Kind:           kind})
+			tags := map[string]string{}
+			if t, ok := m["tags"].(map[string]interface{}); ok {
+				for k, v := range t {
+					if value, ok := v.(string); ok {
+						tags[k] = value
+					}
+				}
+			}
+
+			resource := &azqr.Resource{
+				ID:             m["id"].(string),
+				SubscriptionID: m["subscriptionId"].(string),
+				ResourceGroup:  resourceGroup,
+				Location:       location,
+				Type:           m["type"].(string),
+				Name:           m["name"].(string),
+				SkuName:        skuName,
+				SkuTier:        skuTier,
+				Kind:           kind,
+				Tags:           tags}
+
+			// excluded resources are added too, so the selectors also exclude their APRL and AZQR results
+			filters.Azqr.AddResource(resource)
+			if filters.Azqr.IsServiceExcluded(resource.ID) {
+				continue
+			}
+
+			resources = append(resources, resource)
 		}
 	}
-	return resources
+	return resources, nil
 }
 
-func (sc ResourceScanner) GetCountPerResourceType(ctx context.Context, cred azcore.TokenCredential, subscriptions map[string]string, recommendations map[string]map[string]azqr.AprlRecommendation) []azqr.ResourceTypeCount {
+func (sc ResourceScanner) GetCountPerResourceType(ctx context.Context, cred azcore.TokenCredential, subscriptions map[string]string, recommendations map[string]map[string]azqr.AprlRecommendation) ([]azqr.ResourceTypeCount, error) {
 	azqr.LogResourceTypeScan("Resource Count per Subscription and Type")
 
-	graphClient := graph.NewGraphQuery(cred)
+	graphClient, err := graph.NewGraphQuery(cred)
+	if err != nil {
+		return nil, err
+	}
+	graphClient.ScopeToManagementGroup(sc.ManagementGroup)
 	query := "resources | summarize count() by subscriptionId, type | order by subscriptionId, type"
 	log.Debug().Msg(query)
 	subs := make([]*string, 0, len(subscriptions))
 	for s := range subscriptions {
 		subs = append(subs, &s)
 	}
-	result := graphClient.Query(ctx, query, subs)
+	result, err := graphClient.Query(ctx, query, subs)
+	if err != nil {
+		return nil, err
+	}
 	resources := make([]azqr.ResourceTypeCount, len(result.Data))
 	if result.Data != nil {
 		for i, row := range result.Data {
@@ -100,7 +129,7 @@ func (sc ResourceScanner) GetCountPerResourceType(ctx context.Context, cred azco
 			}
 		}
 	}
-	return resources
+	return resources, nil
 }
 
 func (sc ResourceScanner) isAvailableInAPRL(resourceType string, recommendations map[string]map[string]azqr.AprlRecommendation) string {
@@ -42,13 +42,13 @@ func (c *StorageScanner) Scan(scanContext *azqr.ScanContext) ([]azqr.AzqrService
 	for _, storage := range storage {
 		resourceGroupName := azqr.GetResourceGroupFromResourceID(*storage.ID)
 
-		scanContext.BlobServiceProperties = nil
+		storageContext := scanContext.ForResource()
 		blobServicesProperties, err := c.blobServicesClient.GetServiceProperties(c.config.Ctx, resourceGroupName, *storage.Name, nil)
 		if err == nil {
-			scanContext.BlobServiceProperties = &blobServicesProperties
+			storageContext.BlobServiceProperties = &blobServicesProperties
 		}
 
-		rr := engine.EvaluateRecommendations(rules, storage, scanContext)
+		rr := engine.EvaluateRecommendations(rules, storage, storageContext)
 
 		results = append(results, azqr.AzqrServiceResult{
 			SubscriptionID:   c.config.SubscriptionID,
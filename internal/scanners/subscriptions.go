@@ -2,6 +2,8 @@ package scanners
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/Azure/azqr/internal/azqr"
 	"github.com/Azure/azqr/internal/to"
@@ -13,10 +15,12 @@ import (
 
 type SubcriptionScanner struct{}
 
-func (sc SubcriptionScanner) ListSubscriptions(ctx context.Context, cred azcore.TokenCredential, subscriptionID string, filters *azqr.Filters, options *arm.ClientOptions) map[string]string {
+// ListSubscriptions - Returns the enabled subscriptions that are not filtered out. Key is subscription ID, value is subscription name.
+// When managementGroupSubscriptions is not nil, only its subscriptions are returned.
+func (sc SubcriptionScanner) ListSubscriptions(ctx context.Context, cred azcore.TokenCredential, subscriptionID string, managementGroupSubscriptions map[string]string, filters *azqr.Filters, options *arm.ClientOptions) (map[string]string, error) {
 	client, err := armsubscription.NewSubscriptionsClient(cred, options)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create subscriptions client")
+		return nil, fmt.Errorf("failed to create subscriptions client: %w", err)
 	}
 
 	resultPager := client.NewListPager(nil)
@@ -25,7 +29,7 @@ func (sc SubcriptionScanner) ListSubscriptions(ctx context.Context, cred azcore.
 	for resultPager.More() {
 		pageResp, err := resultPager.NextPage(ctx)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to list subscriptions")
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 		}
 
 		for _, s := range pageResp.Value {
@@ -41,6 +45,10 @@ func (sc SubcriptionScanner) ListSubscriptions(ctx context.Context, cred azcore.
 		// if subscriptionID is empty, return filtered subscriptions. Otherwise, return only the specified subscription
 		sid := *s.SubscriptionID
 		if subscriptionID == "" || subscriptionID == sid {
+			if _, ok := managementGroupSubscriptions[strings.ToLower(sid)]; managementGroupSubscriptions != nil && !ok {
+				log.Debug().Msgf("Skipping subscriptions/...%s. It is not in the management group", sid[29:])
+				continue
+			}
 			if filters.Azqr.IsSubscriptionExcluded(sid) {
 				log.Info().Msgf("Skipping subscriptions/...%s", sid[29:])
 				continue
@@ -49,5 +57,5 @@ func (sc SubcriptionScanner) ListSubscriptions(ctx context.Context, cred azcore.
 		}
 	}
 
-	return result
+	return result, nil
 }
@@ -0,0 +1,137 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+	"github.com/rs/zerolog/log"
+)
+
+// expiredSuppression is a suppression of the filters that expired, with the number of findings it no longer suppresses
+type expiredSuppression struct {
+	suppression *azqr.Suppression
+	reactivated int
+}
+
+// suppressFindings moves the APRL and AZQR findings with an active suppression, or whose resource opts out of
+// the recommendation with the ignore tag, to the suppressed findings of the report. Findings of expired suppressions
+// stay in the report. It returns every expired suppression of the filters.
+func suppressFindings(data *renderers.ReportData, filter *azqr.AzqrFilter, ignoreTag string, now time.Time) []expiredSuppression {
+	reactivated := map[*azqr.Suppression]int{}
+
+	resources := map[string]*azqr.Resource{}
+	for _, r := range data.Resources {
+		resources[strings.ToLower(r.ID)] = r
+	}
+
+	// suppressed returns the suppression details of a finding, if any, and counts the findings of expired suppressions
+	suppressed := func(recommendationID, resourceID string) *azqr.SuppressedResult {
+		s := filter.Suppression(recommendationID, resourceID, now)
+		if s != nil && !s.IsExpired(now) {
+			return &azqr.SuppressedResult{Justification: s.Justification, Owner: s.Owner, Expires: s.Expires}
+		}
+
+		if r, ok := resources[strings.ToLower(resourceID)]; ok && ignoreTag != "" {
+			if value, ok := r.OptOut(ignoreTag, recommendationID); ok {
+				return &azqr.SuppressedResult{
+					Justification: fmt.Sprintf("Opted out with the %s tag of the resource", ignoreTag),
+					Tag:           fmt.Sprintf("%s=%s", ignoreTag, value),
+				}
+			}
+		}
+
+		if s != nil {
+			reactivated[s]++
+		}
+		return nil
+	}
+
+	aprl := []azqr.AprlResult{}
+	for _, r := range data.AprlData {
+		s := suppressed(r.RecommendationID, r.ResourceID)
+		if s == nil {
+			aprl = append(aprl, r)
+			continue
+		}
+		s.Source = r.Source
+		s.RecommendationID = r.RecommendationID
+		s.Recommendation = r.Recommendation
+		s.Category = r.Category
+		s.Impact = r.Impact
+		s.ResourceType = r.ResourceType
+		s.SubscriptionID = r.SubscriptionID
+		s.SubscriptionName = r.SubscriptionName
+		s.ResourceGroup = r.ResourceGroup
+		s.Name = r.Name
+		s.ResourceID = r.ResourceID
+		data.Suppressed = append(data.Suppressed, *s)
+	}
+	data.AprlData = aprl
+
+	for i, d := range data.AzqrData {
+		ids := make([]string, 0, len(d.Recommendations))
+		for id := range d.Recommendations {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		// the recommendations are copied, as the results can be shared with the checkpoints of the scan
+		recommendations := make(map[string]azqr.AzqrResult, len(d.Recommendations))
+		for _, id := range ids {
+			r := d.Recommendations[id]
+			var s *azqr.SuppressedResult
+			if r.NotCompliant {
+				s = suppressed(r.RecommendationID, d.ResourceID())
+			}
+			if s == nil {
+				recommendations[id] = r
+				continue
+			}
+			s.Source = "AZQR"
+			s.RecommendationID = r.RecommendationID
+			s.Recommendation = r.Recommendation
+			s.Category = r.Category
+			s.Impact = r.Impact
+			s.ResourceType = d.Type
+			s.SubscriptionID = d.SubscriptionID
+			s.SubscriptionName = d.SubscriptionName
+			s.ResourceGroup = d.ResourceGroup
+			s.Name = d.ServiceName
+			s.ResourceID = d.ResourceID()
+			data.Suppressed = append(data.Suppressed, *s)
+		}
+		data.AzqrData[i].Recommendations = recommendations
+	}
+
+	expired := []expiredSuppression{}
+	for _, s := range filter.Suppressions {
+		if s.IsExpired(now) {
+			expired = append(expired, expiredSuppression{suppression: s, reactivated: reactivated[s]})
+		}
+	}
+	return expired
+}
+
+// logSuppressions prints the number of suppressed findings and calls out the expired suppressions
+func logSuppressions(data *renderers.ReportData, expired []expiredSuppression) {
+	if len(data.Suppressed) > 0 {
+		log.Info().Msgf("%d findings suppressed. Check the Suppressed sheet of the report.", len(data.Suppressed))
+	}
+
+	for _, e := range expired {
+		s := e.suppression
+		scope := s.Scope
+		if scope == "" {
+			scope = "all resources"
+		}
+		log.Warn().Msgf("Suppression of %s on %s (owner: %s) expired on %s. %d findings are active again.",
+			s.RecommendationID, scope, s.Owner, s.Expires, e.reactivated)
+	}
+}
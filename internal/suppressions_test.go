@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azqr/internal/azqr"
+	"github.com/Azure/azqr/internal/renderers"
+)
+
+const suppressionsSubscription = "00000000-0000-0000-0000-000000000001"
+
+func Test_suppressFindings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	content := `
+azqr:
+  suppressions:
+    - recommendationId: aprl-1
+      scope: /subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg1
+      justification: Accepted risk
+      owner: team-a
+      expires: 2030-01-31
+    - recommendationId: st-001
+      scope: 00000000-0000-0000-0000-000000000001
+      justification: Private endpoints are not used
+      owner: team-b
+      expires: 2030-01-31
+    - recommendationId: st-002
+      justification: Migration planned
+      owner: team-c
+      expires: 2020-01-31
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filters, err := azqr.LoadFilters(file)
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+
+	data := renderers.NewReportData("", false)
+	rg1 := "/subscriptions/" + suppressionsSubscription + "/resourceGroups/rg1"
+	rg2 := "/subscriptions/" + suppressionsSubscription + "/resourceGroups/rg2"
+	data.AprlData = []azqr.AprlResult{
+		{RecommendationID: "aprl-1", ResourceID: rg1 + "/providers/Microsoft.Web/sites/a", Source: "APRL"},
+		{RecommendationID: "aprl-1", ResourceID: rg2 + "/providers/Microsoft.Web/sites/b", Source: "APRL"},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{{
+		SubscriptionID: suppressionsSubscription, ResourceGroup: "rg2", Type: "Microsoft.Storage/storageAccounts", ServiceName: "st",
+		Recommendations: map[string]azqr.AzqrResult{
+			"st-001": {RecommendationID: "st-001", NotCompliant: true},
+			"st-002": {RecommendationID: "st-002", NotCompliant: true},
+			"st-003": {RecommendationID: "st-003"},
+		},
+	}}
+
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	expired := suppressFindings(&data, filters.Azqr, "azqr-ignore", now)
+
+	if len(data.AprlData) != 1 || data.AprlData[0].ResourceID != rg2+"/providers/Microsoft.Web/sites/b" {
+		t.Errorf("suppressFindings() aprl = %v", data.AprlData)
+	}
+	if _, ok := data.AzqrData[0].Recommendations["st-001"]; ok {
+		t.Errorf("suppressFindings() st-001 was not suppressed")
+	}
+	if _, ok := data.AzqrData[0].Recommendations["st-002"]; !ok {
+		t.Errorf("suppressFindings() st-002 of an expired suppression was suppressed")
+	}
+
+	if len(data.Suppressed) != 2 {
+		t.Fatalf("suppressFindings() suppressed = %v", data.Suppressed)
+	}
+	if s := data.Suppressed[0]; s.RecommendationID != "aprl-1" || s.Owner != "team-a" || s.Justification != "Accepted risk" || s.Expires != "2030-01-31" {
+		t.Errorf("suppressFindings() suppressed = %v", s)
+	}
+	if s := data.Suppressed[1]; s.RecommendationID != "st-001" || s.Source != "AZQR" || s.Name != "st" {
+		t.Errorf("suppressFindings() suppressed = %v", s)
+	}
+
+	if len(expired) != 1 || expired[0].suppression.RecommendationID != "st-002" || expired[0].reactivated != 1 {
+		t.Errorf("suppressFindings() expired = %v", expired)
+	}
+}
+
+func Test_suppressFindings_IgnoreTag(t *testing.T) {
+	rg := "/subscriptions/" + suppressionsSubscription + "/resourceGroups/rg"
+	site := rg + "/providers/Microsoft.Web/sites/site"
+	st := rg + "/providers/Microsoft.Storage/storageAccounts/st"
+
+	data := renderers.NewReportData("", false)
+	data.Resources = []*azqr.Resource{
+		{ID: site, Tags: map[string]string{"AZQR-Ignore": "aprl-1, aprl-2"}},
+		{ID: st, Tags: map[string]string{"azqr-ignore": "*"}},
+	}
+	data.AprlData = []azqr.AprlResult{
+		{RecommendationID: "aprl-1", ResourceID: site},
+		{RecommendationID: "aprl-3", ResourceID: site},
+	}
+	data.AzqrData = []azqr.AzqrServiceResult{{
+		SubscriptionID: suppressionsSubscription, ResourceGroup: "rg", Type: "Microsoft.Storage/storageAccounts", ServiceName: "st",
+		Recommendations: map[string]azqr.AzqrResult{
+			"st-001": {RecommendationID: "st-001", NotCompliant: true},
+		},
+	}}
+
+	filters, err := azqr.LoadFilters("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suppressFindings(&data, filters.Azqr, "azqr-ignore", time.Now())
+
+	if len(data.AprlData) != 1 || data.AprlData[0].RecommendationID != "aprl-3" {
+		t.Errorf("suppressFindings() aprl = %v", data.AprlData)
+	}
+	if len(data.AzqrData[0].Recommendations) != 0 {
+		t.Errorf("suppressFindings() azqr = %v", data.AzqrData[0].Recommendations)
+	}
+
+	want := []string{"azqr-ignore=aprl-1, aprl-2", "azqr-ignore=*"}
+	if len(data.Suppressed) != len(want) {
+		t.Fatalf("suppressFindings() suppressed = %v", data.Suppressed)
+	}
+	for i, w := range want {
+		if data.Suppressed[i].Tag != w || data.Suppressed[i].Justification == "" {
+			t.Errorf("suppressFindings() suppressed = %v, want tag %s", data.Suppressed[i], w)
+		}
+	}
+
+	// the tag is ignored if no tag name is given
+	data.AprlData = []azqr.AprlResult{{RecommendationID: "aprl-1", ResourceID: site}}
+	suppressFindings(&data, filters.Azqr, "", time.Now())
+	if len(data.AprlData) != 1 {
+		t.Errorf("suppressFindings() aprl = %v, want the finding", data.AprlData)
+	}
+}
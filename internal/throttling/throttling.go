@@ -0,0 +1,200 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package throttling
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultMaxConcurrency - Default number of in-flight ARM requests
+	DefaultMaxConcurrency = 50
+
+	// lowRemainingRequests - Remaining requests reported by ARM below which requests to the subscription are slowed down
+	lowRemainingRequests = 10
+	// lowRemainingDelay - Pause applied to a subscription when its remaining requests are low
+	lowRemainingDelay = 2 * time.Second
+	// maxPause - Longest pause honoured from a Retry-After header
+	maxPause = 5 * time.Minute
+
+	// tenantScope - Scope of the requests that are not bound to a subscription
+	tenantScope = ""
+)
+
+// Limiter - azcore pipeline policy that bounds the number of in-flight ARM requests
+// and pauses the requests to a subscription when ARM reports that it is being throttled.
+// The same Limiter must be shared by all clients, so the limit is global to the scan.
+type Limiter struct {
+	slots       chan struct{}
+	mu          sync.Mutex
+	pausedUntil map[string]time.Time
+}
+
+// NewLimiter - Creates a Limiter that allows up to maxConcurrency in-flight requests
+func NewLimiter(maxConcurrency int) *Limiter {
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+	return &Limiter{
+		slots:       make(chan struct{}, maxConcurrency),
+		pausedUntil: map[string]time.Time{},
+	}
+}
+
+// Do - Implements policy.Policy
+func (l *Limiter) Do(req *policy.Request) (*http.Response, error) {
+	ctx := req.Raw().Context()
+	scope := subscriptionScope(req.Raw().URL.Path)
+
+	if err := l.wait(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resp, err := req.Next()
+	<-l.slots
+
+	if resp != nil {
+		l.observe(scope, resp)
+	}
+	return resp, err
+}
+
+// wait blocks while the tenant or the subscription of the request are paused
+func (l *Limiter) wait(ctx context.Context, scope string) error {
+	for {
+		l.mu.Lock()
+		until := l.pausedUntil[scope]
+		if t := l.pausedUntil[tenantScope]; t.After(until) {
+			until = t
+		}
+		l.mu.Unlock()
+
+		d := time.Until(until)
+		if d <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// observe pauses the scope of a response that was throttled or that reports few remaining requests
+func (l *Limiter) observe(scope string, resp *http.Response) {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if d := RetryAfter(resp.Header); d > 0 {
+			l.pause(scope, d)
+		}
+		return
+	}
+
+	for name, values := range resp.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "x-ms-ratelimit-remaining-") || len(values) == 0 {
+			continue
+		}
+
+		if remaining, ok := minRemaining(values[0]); ok && remaining < lowRemainingRequests {
+			if strings.Contains(name, "tenant") {
+				l.pause(tenantScope, lowRemainingDelay)
+			} else {
+				l.pause(scope, lowRemainingDelay)
+			}
+		}
+	}
+}
+
+func (l *Limiter) pause(scope string, d time.Duration) {
+	if d > maxPause {
+		d = maxPause
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(l.pausedUntil[scope]) {
+		l.pausedUntil[scope] = until
+		if scope == tenantScope {
+			log.Debug().Msgf("ARM throttling detected. Pausing requests for %s", d)
+		} else {
+			log.Debug().Msgf("ARM throttling detected. Pausing requests to subscription %s for %s", scope, d)
+		}
+	}
+}
+
+// RetryAfter - Returns the delay requested by the retry-after-ms, x-ms-retry-after-ms or Retry-After headers, or 0 if there is none
+func RetryAfter(header http.Header) time.Duration {
+	for _, h := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if v := header.Get(h); v != "" {
+			if ms, err := strconv.Atoi(v); err == nil && ms > 0 {
+				return time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s > 0 {
+			return time.Duration(s) * time.Second
+		}
+		return 0
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// minRemaining parses the value of a x-ms-ratelimit-remaining-* header. It is either a number, or a list of policies
+// with their remaining requests (e.g. Microsoft.Compute/HighCostGet3Min;107,Microsoft.Compute/HighCostGet30Min;567)
+func minRemaining(value string) (int, bool) {
+	found := false
+	min := 0
+	for _, part := range strings.Split(value, ",") {
+		if i := strings.LastIndex(part, ";"); i >= 0 {
+			part = part[i+1:]
+		}
+		remaining, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if !found || remaining < min {
+			min = remaining
+			found = true
+		}
+	}
+	return min, found
+}
+
+// subscriptionScope returns the subscription id of an ARM request path, or the tenant scope
+func subscriptionScope(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions") {
+		return strings.ToLower(parts[1])
+	}
+	return tenantScope
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+
+package throttling
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// fakeARMTransport answers every request after a delay, records the maximum number of concurrent requests
+// and returns the headers configured for the subscription of the request
+type fakeARMTransport struct {
+	mu       sync.Mutex
+	delay    time.Duration
+	inFlight int
+	maxSeen  int
+	headers  map[string]http.Header
+	requests map[string][]time.Time
+}
+
+func (f *fakeARMTransport) Do(req *http.Request) (*http.Response, error) {
+	scope := subscriptionScope(req.URL.Path)
+
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxSeen {
+		f.maxSeen = f.inFlight
+	}
+	f.requests[scope] = append(f.requests[scope], time.Now())
+	header := f.headers[scope]
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	status := http.StatusOK
+	if header == nil {
+		header = http.Header{}
+	} else if header.Get("Retry-After") != "" || header.Get("retry-after-ms") != "" {
+		status = http.StatusTooManyRequests
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header.Clone(),
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func newFakeARMTransport(delay time.Duration) *fakeARMTransport {
+	return &fakeARMTransport{
+		delay:    delay,
+		headers:  map[string]http.Header{},
+		requests: map[string][]time.Time{},
+	}
+}
+
+func send(t *testing.T, pipeline runtime.Pipeline, path string) {
+	t.Helper()
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://management.azure.com"+path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resp.Body.Close()
+}
+
+func newTestPipeline(limiter *Limiter, transport policy.Transporter) runtime.Pipeline {
+	return runtime.NewPipeline("azqr", "test", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:        transport,
+		Retry:            policy.RetryOptions{MaxRetries: -1},
+		PerRetryPolicies: []policy.Policy{limiter},
+	})
+}
+
+func TestLimiter_MaxConcurrency(t *testing.T) {
+	transport := newFakeARMTransport(20 * time.Millisecond)
+	pipeline := newTestPipeline(NewLimiter(3), transport)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			send(t, pipeline, "/subscriptions/s"+strings.Repeat("x", i%4)+"/resources")
+		}(i)
+	}
+	wg.Wait()
+
+	if transport.maxSeen != 3 {
+		t.Errorf("Limiter allowed %d concurrent requests, want 3", transport.maxSeen)
+	}
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantPaused []string
+		wantDelay  time.Duration
+	}{
+		{
+			name:       "retry after pauses the subscription",
+			header:     http.Header{"Retry-After-Ms": []string{"200"}},
+			wantPaused: []string{"s1"},
+			wantDelay:  200 * time.Millisecond,
+		},
+		{
+			name:       "low remaining subscription reads pause the subscription",
+			header:     http.Header{"X-Ms-Ratelimit-Remaining-Subscription-Reads": []string{"5"}},
+			wantPaused: []string{"s1"},
+			wantDelay:  lowRemainingDelay,
+		},
+		{
+			name:       "low remaining tenant reads pause all subscriptions",
+			header:     http.Header{"X-Ms-Ratelimit-Remaining-Tenant-Reads": []string{"5"}},
+			wantPaused: []string{"s1", "s2"},
+			wantDelay:  lowRemainingDelay,
+		},
+		{
+			name:      "enough remaining reads",
+			header:    http.Header{"X-Ms-Ratelimit-Remaining-Subscription-Reads": []string{"11999"}},
+			wantDelay: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newFakeARMTransport(0)
+			transport.headers["s1"] = tt.header
+			limiter := NewLimiter(10)
+			pipeline := newTestPipeline(limiter, transport)
+
+			send(t, pipeline, "/subscriptions/s1/resources")
+
+			for _, s := range []string{"s1", "s2"} {
+				paused := time.Until(limiter.pausedUntil[s]) > 0 || time.Until(limiter.pausedUntil[tenantScope]) > 0
+				want := false
+				for _, p := range tt.wantPaused {
+					want = want || p == s
+				}
+				if paused != want {
+					t.Errorf("Limiter paused %s = %v, want %v", s, paused, want)
+				}
+			}
+
+			// the pause is honoured by the next request to the subscription
+			if tt.wantDelay > 0 && tt.wantDelay < time.Second {
+				transport.headers["s1"] = nil
+				send(t, pipeline, "/subscriptions/s1/resources")
+				requests := transport.requests["s1"]
+				if d := requests[1].Sub(requests[0]); d < tt.wantDelay {
+					t.Errorf("Limiter waited %s, want at least %s", d, tt.wantDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiter_Canceled(t *testing.T) {
+	limiter := NewLimiter(1)
+	limiter.pause("s1", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := runtime.NewRequest(ctx, http.MethodGet, "https://management.azure.com/subscriptions/s1/resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestPipeline(limiter, newFakeARMTransport(0)).Do(req); err == nil {
+		t.Errorf("Limiter.Do() error = nil, want context canceled")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "none", header: http.Header{}, want: 0},
+		{name: "retry-after-ms", header: http.Header{"Retry-After-Ms": []string{"1500"}}, want: 1500 * time.Millisecond},
+		{name: "x-ms-retry-after-ms", header: http.Header{"X-Ms-Retry-After-Ms": []string{"300"}}, want: 300 * time.Millisecond},
+		{name: "seconds", header: http.Header{"Retry-After": []string{"7"}}, want: 7 * time.Second},
+		{name: "milliseconds take precedence", header: http.Header{"Retry-After": []string{"7"}, "Retry-After-Ms": []string{"10"}}, want: 10 * time.Millisecond},
+		{name: "zero", header: http.Header{"Retry-After": []string{"0"}}, want: 0},
+		{name: "invalid", header: http.Header{"Retry-After": []string{"soon"}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryAfter(tt.header); got != tt.want {
+				t.Errorf("RetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("http date", func(t *testing.T) {
+		header := http.Header{"Retry-After": []string{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}
+		if got := RetryAfter(header); got <= 58*time.Second || got > time.Minute {
+			t.Errorf("RetryAfter() = %v, want about 1m", got)
+		}
+	})
+}
+
+func Test_minRemaining(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   int
+		wantOk bool
+	}{
+		{value: "11999", want: 11999, wantOk: true},
+		{value: "Microsoft.Compute/HighCostGet3Min;107,Microsoft.Compute/HighCostGet30Min;567", want: 107, wantOk: true},
+		{value: "Microsoft.Compute/GetVMScaleSet3Min;197, Microsoft.Compute/GetVMScaleSet30Min;1297", want: 197, wantOk: true},
+		{value: "", want: 0, wantOk: false},
+		{value: "unknown", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := minRemaining(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("minRemaining() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_subscriptionScope(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/subscriptions/ABC/resourceGroups/rg/providers/Microsoft.Web/sites", want: "abc"},
+		{path: "/subscriptions/abc", want: "abc"},
+		{path: "/subscriptions", want: tenantScope},
+		{path: "/providers/Microsoft.ResourceGraph/resources", want: tenantScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := subscriptionScope(tt.path); got != tt.want {
+				t.Errorf("subscriptionScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}